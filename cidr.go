@@ -0,0 +1,142 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// cidrRule is a compiled rule of AllowOriginCIDRs.
+type cidrRule struct {
+	// schema is like "http://", empty for any schema
+	schema string
+	ipNet  *net.IPNet
+	ports  portRange
+}
+
+// parseCIDRRule parses a rule like 10.0.0.0/8, http://10.12.0.0/16:8080 or
+// https://[fd00::/8]:8443-8450. The schema and the port are optional.
+func parseCIDRRule(rule string) (cidrRule, error) {
+	r := cidrRule{ports: portRange{any: true}}
+	rest := strings.ToLower(strings.TrimSpace(rule))
+	if i := strings.Index(rest, "://"); i >= 0 {
+		r.schema, rest = rest[:i+3], rest[i+3:]
+	}
+
+	var block, port string
+	if strings.HasPrefix(rest, "[") {
+		end := strings.IndexByte(rest, ']')
+		if end < 0 {
+			return r, errors.New("missing ] in " + rule)
+		}
+		block, port = rest[1:end], rest[end+1:]
+		if !strings.Contains(block, ":") {
+			return r, errors.New("only IPv6 blocks are in brackets in " + rule)
+		}
+	} else {
+		block = rest
+		if i := strings.IndexByte(rest, ':'); i >= 0 {
+			block, port = rest[:i], rest[i:]
+		}
+	}
+
+	if !strings.Contains(block, "/") {
+		ip := net.ParseIP(block)
+		if ip == nil {
+			return r, errors.New("bad IP or CIDR block in " + rule)
+		}
+		bits := 8 * net.IPv6len
+		if ip.To4() != nil {
+			bits = 8 * net.IPv4len
+		}
+		block += "/" + strconv.Itoa(bits)
+	}
+	_, ipNet, err := net.ParseCIDR(block)
+	if err != nil {
+		return r, errors.New("bad IP or CIDR block in " + rule)
+	}
+	r.ipNet = ipNet
+
+	switch {
+	case len(port) == 0, port == ":*":
+	case port[0] != ':':
+		return r, errors.New("bad port in " + rule)
+	default:
+		bounds := strings.SplitN(port[1:], "-", 2)
+		low, err1 := parsePort(bounds[0])
+		high, err2 := low, error(nil)
+		if len(bounds) == 2 {
+			high, err2 = parsePort(bounds[1])
+		}
+		if err1 != nil || err2 != nil || low > high {
+			return r, errors.New("bad port in " + rule + ", it must be like 8080 or 8443-8450")
+		}
+		r.ports = portRange{low: low, high: high}
+	}
+	return r, nil
+}
+
+// matchCIDR reports whether the host of the origin is an IP address matching a rule.
+func (cors *cors) matchCIDR(origin string) bool {
+	i := strings.Index(origin, "://")
+	if i < 0 {
+		return false
+	}
+	schema, host := origin[:i+3], origin[i+3:]
+	key, port, hasPort := splitOriginPort(origin)
+	if hasPort {
+		host = key[i+3:]
+	}
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		host = host[1 : len(host)-1]
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	p := -1
+	if !hasPort {
+		if d, ok := defaultPorts[schema]; ok {
+			p = d
+		}
+	} else if isPort(port) {
+		p, _ = strconv.Atoi(port)
+	} else {
+		return false
+	}
+	for _, r := range cors.allowCIDRs {
+		if (len(r.schema) == 0 || r.schema == schema) && r.ipNet.Contains(ip) && r.ports.match(p) {
+			return true
+		}
+	}
+	return false
+}
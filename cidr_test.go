@@ -0,0 +1,126 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"net"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func TestParseCIDRRule(t *testing.T) {
+	_, block, _ := net.ParseCIDR("10.0.0.0/8")
+	r, err := parseCIDRRule("10.0.0.0/8")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, cidrRule{ipNet: block, ports: portRange{any: true}}, r)
+
+	_, block, _ = net.ParseCIDR("10.12.0.0/16")
+	r, err = parseCIDRRule("HTTP://10.12.0.0/16:8080")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, cidrRule{schema: "http://", ipNet: block, ports: portRange{low: 8080, high: 8080}}, r)
+
+	_, block, _ = net.ParseCIDR("fd00::/8")
+	r, err = parseCIDRRule("https://[fd00::/8]:8443-8450")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, cidrRule{schema: "https://", ipNet: block, ports: portRange{low: 8443, high: 8450}}, r)
+
+	_, block, _ = net.ParseCIDR("192.168.1.10/32")
+	r, err = parseCIDRRule("192.168.1.10:*")
+	assert.Nil(t, err)
+	assert.DeepEqual(t, cidrRule{ipNet: block, ports: portRange{any: true}}, r)
+
+	for _, rule := range []string{
+		"10.0.0.0/33",
+		"10.0.0/8",
+		"example.com",
+		"[fd00::/8",
+		"fd00::/8",
+		"[10.0.0.0/8]",
+		"10.0.0.0/8:0",
+		"10.0.0.0/8:8450-8443",
+		"10.0.0.0/8:http",
+		"[fd00::/8]8443",
+	} {
+		_, err = parseCIDRRule(rule)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestAllowOriginCIDRs(t *testing.T) {
+	router := newTestRouter(Config{
+		AllowOriginCIDRs: []string{"http://10.12.0.0/16:8080", "https://[fd00::/8]", "192.168.0.0/24:3000-3010"},
+		DenyOrigins:      []string{"http://10.12.6.6:8080"},
+	})
+
+	for _, c := range []struct {
+		origin  string
+		allowed bool
+	}{
+		{"http://10.12.3.4:8080", true},
+		{"http://10.12.3.4", false},
+		{"http://10.12.3.4:8081", false},
+		{"https://10.12.3.4:8080", false},
+		{"http://10.13.3.4:8080", false},
+		{"http://10.12.6.6:8080", false},
+		{"https://[fd12::1]", true},
+		{"https://[fd12::1]:8443", true},
+		{"https://[fd12::1]:", false},
+		{"https://[fd12::1]:abc", false},
+		{"http://[fd12::1]", false},
+		{"https://[fe80::1]", false},
+		{"http://192.168.0.7:3005", true},
+		{"https://192.168.0.7:3010", true},
+		{"http://192.168.0.7:3011", false},
+		{"http://192.168.0.7", false},
+		{"http://192.168.0.7.evil.com:3005", false},
+		{"http://evil.com:3005", false},
+	} {
+		w := performRequest(router, "GET", c.origin)
+		if c.allowed {
+			assert.DeepEqual(t, c.origin, w.Header().Get("Access-Control-Allow-Origin"))
+		} else {
+			assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+		}
+	}
+}
+
+func TestAllowOriginCIDRsValidate(t *testing.T) {
+	assert.Nil(t, Config{AllowOriginCIDRs: []string{"10.0.0.0/8"}}.Validate())
+	assert.DeepEqual(t, "bad origin CIDR: bad IP or CIDR block in 10.0.0.0/33",
+		Config{AllowOriginCIDRs: []string{"10.0.0.0/33"}}.Validate().Error())
+	assert.DeepEqual(t, "bad origin CIDR: schema must be one of http://,https://",
+		Config{AllowOriginCIDRs: []string{"ws://10.0.0.0/8"}}.Validate().Error())
+	assert.Nil(t, Config{AllowOriginCIDRs: []string{"ws://10.0.0.0/8"}, AllowWebSockets: true}.Validate())
+	assert.NotNil(t, Config{AllowOriginCIDRs: []string{"10.0.0.0/8"}, AllowAllOrigins: true}.Validate())
+
+	router := newOptionsRouter(WithOriginCIDRs("10.0.0.0/8"))
+	w := performRequest(router, "GET", "http://10.1.2.3")
+	assert.DeepEqual(t, "http://10.1.2.3", w.Header().Get("Access-Control-Allow-Origin"))
+}
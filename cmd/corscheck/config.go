@@ -0,0 +1,152 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hertz-contrib/cors"
+)
+
+// fileConfig is the JSON form of cors.Config, durations are strings like "12h".
+// AllowOriginFunc and AllowOriginFuncCache can not be set from a file.
+type fileConfig struct {
+	AllowAllOrigins             bool     `json:"allow_all_origins"`
+	AllowOrigins                []string `json:"allow_origins"`
+	AllowMethods                []string `json:"allow_methods"`
+	AllowHeaders                []string `json:"allow_headers"`
+	AllowCredentials            bool     `json:"allow_credentials"`
+	ExposeHeaders               []string `json:"expose_headers"`
+	DynamicExposeHeaders        bool     `json:"dynamic_expose_headers"`
+	DynamicExposeAllow          []string `json:"dynamic_expose_allow"`
+	DynamicExposeDeny           []string `json:"dynamic_expose_deny"`
+	MaxAge                      duration `json:"max_age"`
+	PreflightHandlers           bool     `json:"preflight_handlers"`
+	PreflightOriginRateLimit    float64  `json:"preflight_origin_rate_limit"`
+	PreflightOriginBurst        int      `json:"preflight_origin_burst"`
+	PreflightClientRateLimit    float64  `json:"preflight_client_rate_limit"`
+	PreflightClientBurst        int      `json:"preflight_client_burst"`
+	PreflightRateLimitSize      int      `json:"preflight_rate_limit_size"`
+	AllowWildcard               bool     `json:"allow_wildcard"`
+	AllowPublicSuffixWildcard   bool     `json:"allow_public_suffix_wildcard"`
+	AllowBrowserExtensions      bool     `json:"allow_browser_extensions"`
+	AllowWebSockets             bool     `json:"allow_web_sockets"`
+	AllowFiles                  bool     `json:"allow_files"`
+	AllowLocalhost              bool     `json:"allow_localhost"`
+	AllowOriginCIDRs            []string `json:"allow_origin_cidrs"`
+	AllowTiming                 bool     `json:"allow_timing"`
+	TimingAllowOrigins          []string `json:"timing_allow_origins"`
+	DenyOrigins                 []string `json:"deny_origins"`
+	DenyOriginPatterns          []string `json:"deny_origin_patterns"`
+	ResourceIsolation           bool     `json:"resource_isolation"`
+	ResourceIsolationAllowPaths []string `json:"resource_isolation_allow_paths"`
+	CrossOriginOpenerPolicy     string   `json:"cross_origin_opener_policy"`
+	CrossOriginEmbedderPolicy   string   `json:"cross_origin_embedder_policy"`
+	CrossOriginResourcePolicy   string   `json:"cross_origin_resource_policy"`
+	AllowNullOrigin             bool     `json:"allow_null_origin"`
+	AllowNullOriginCredentials  bool     `json:"allow_null_origin_credentials"`
+	NullOriginMaxAge            duration `json:"null_origin_max_age"`
+}
+
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("bad duration %s: must be a string like \"12h\"", b)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+// loadConfig reads and validates the config file at path.
+func loadConfig(path string) (cors.Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cors.Config{}, err
+	}
+	return parseConfig(b)
+}
+
+func parseConfig(b []byte) (cors.Config, error) {
+	var f fileConfig
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&f); err != nil {
+		return cors.Config{}, fmt.Errorf("bad config: %v", err)
+	}
+	config := cors.Config{
+		AllowAllOrigins:             f.AllowAllOrigins,
+		AllowOrigins:                f.AllowOrigins,
+		AllowMethods:                f.AllowMethods,
+		AllowHeaders:                f.AllowHeaders,
+		AllowCredentials:            f.AllowCredentials,
+		ExposeHeaders:               f.ExposeHeaders,
+		DynamicExposeHeaders:        f.DynamicExposeHeaders,
+		DynamicExposeAllow:          f.DynamicExposeAllow,
+		DynamicExposeDeny:           f.DynamicExposeDeny,
+		MaxAge:                      time.Duration(f.MaxAge),
+		PreflightHandlers:           f.PreflightHandlers,
+		PreflightOriginRateLimit:    f.PreflightOriginRateLimit,
+		PreflightOriginBurst:        f.PreflightOriginBurst,
+		PreflightClientRateLimit:    f.PreflightClientRateLimit,
+		PreflightClientBurst:        f.PreflightClientBurst,
+		PreflightRateLimitSize:      f.PreflightRateLimitSize,
+		AllowWildcard:               f.AllowWildcard,
+		AllowPublicSuffixWildcard:   f.AllowPublicSuffixWildcard,
+		AllowBrowserExtensions:      f.AllowBrowserExtensions,
+		AllowWebSockets:             f.AllowWebSockets,
+		AllowFiles:                  f.AllowFiles,
+		AllowLocalhost:              f.AllowLocalhost,
+		AllowOriginCIDRs:            f.AllowOriginCIDRs,
+		AllowTiming:                 f.AllowTiming,
+		TimingAllowOrigins:          f.TimingAllowOrigins,
+		DenyOrigins:                 f.DenyOrigins,
+		DenyOriginPatterns:          f.DenyOriginPatterns,
+		ResourceIsolation:           f.ResourceIsolation,
+		ResourceIsolationAllowPaths: f.ResourceIsolationAllowPaths,
+		CrossOriginOpenerPolicy:     f.CrossOriginOpenerPolicy,
+		CrossOriginEmbedderPolicy:   f.CrossOriginEmbedderPolicy,
+		CrossOriginResourcePolicy:   f.CrossOriginResourcePolicy,
+		AllowNullOrigin:             f.AllowNullOrigin,
+		AllowNullOriginCredentials:  f.AllowNullOriginCredentials,
+		NullOriginMaxAge:            time.Duration(f.NullOriginMaxAge),
+	}
+	if err := config.Validate(); err != nil {
+		return cors.Config{}, err
+	}
+	return config, nil
+}
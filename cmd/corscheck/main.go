@@ -0,0 +1,258 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+// Command corscheck tells if a request is allowed by a CORS policy, and prints the
+// response headers set by the middleware.
+//
+//	corscheck -config cors.json -origin https://foo.com -method PUT -header "Content-Type: application/json"
+//
+// The exit code is 0 if the browser would allow the request, 1 if it would not and 2 on
+// bad usage or a bad config.
+//
+// With -probe, corscheck sends a matrix of preflight and actual requests to a running
+// server instead, and prints where its responses differ from the config. Actual requests
+// are only sent with GET and HEAD. The exit code is 1 if there are differences.
+//
+//	corscheck -config cors.json -probe https://api.foo.com
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/hertz-contrib/cors"
+	"github.com/hertz-contrib/cors/corstest"
+)
+
+const (
+	exitAllowed  = 0
+	exitRejected = 1
+	exitUsage    = 2
+)
+
+// headerFlag collects the repeated -header flags.
+type headerFlag map[string]string
+
+func (h headerFlag) String() string {
+	return ""
+}
+
+func (h headerFlag) Set(v string) error {
+	kv := strings.SplitN(v, ":", 2)
+	key := strings.TrimSpace(kv[0])
+	if len(key) == 0 {
+		return fmt.Errorf("bad header %q", v)
+	}
+	if len(kv) == 2 {
+		h[key] = strings.TrimSpace(kv[1])
+	} else {
+		h[key] = ""
+	}
+	return nil
+}
+
+// listFlag collects a repeated flag.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("corscheck", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", "", "path of the JSON config file")
+	origin := flags.String("origin", "", "Origin of the request")
+	method := flags.String("method", consts.MethodGet, "method of the request")
+	path := flags.String("path", "/", "path of the request")
+	host := flags.String("host", "", "Host of the request")
+	credentials := flags.Bool("credentials", false, "the request is made with credentials")
+	headers := headerFlag{}
+	flags.Var(headers, "header", "header set by the script as \"Name: value\", can be repeated")
+	probeURL := flags.String("probe", "", "base URL of a running server to probe instead of checking one request")
+	var probeOrigins listFlag
+	flags.Var(&probeOrigins, "probe-origin", "origin to probe, can be repeated. Default is the origins of the config")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if len(*configPath) == 0 || flags.NArg() > 0 {
+		fmt.Fprintln(stderr, "usage: corscheck -config file [-origin origin] [-method method] [-header header]...")
+		fmt.Fprintln(stderr, "       corscheck -config file -probe url [-probe-origin origin]...")
+		return exitUsage
+	}
+	c, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "corscheck: %v\n", err)
+		return exitUsage
+	}
+
+	if len(*probeURL) > 0 {
+		return probe(c, *probeURL, *path, probeOrigins, stdout, stderr)
+	}
+	r := &corstest.BrowserRequest{
+		Mode:        corstest.ModeCors,
+		Credentials: *credentials,
+		Origin:      *origin,
+		Method:      strings.ToUpper(*method),
+		Headers:     headers,
+	}
+	return check(c, r, *path, *host, stdout)
+}
+
+// check prints the responses of the middleware to the request and the decision of the browser.
+func check(c cors.Config, r *corstest.BrowserRequest, path, host string, stdout io.Writer) int {
+	crossOrigin := len(r.Origin) > 0 && !isSameOrigin(r.Origin, host)
+	if !crossOrigin {
+		r.Mode = corstest.ModeSameOrigin
+	}
+	r.Path = path
+	s := newSimulator(c)
+	preflightRequest, actualRequest := r.Requests()
+
+	var preflight *corstest.Response
+	if preflightRequest != nil {
+		w := s.do(preflightRequest.WithHost(host))
+		preflight = corstest.ResponseOf(w)
+		printResponse(stdout, "preflight", w)
+	}
+	w := s.do(actualRequest.WithHost(host))
+	printResponse(stdout, "actual", w)
+
+	switch {
+	case len(s.reason) > 0:
+		fmt.Fprintf(stdout, "decision: rejected: %s\n", s.reason)
+	case !crossOrigin:
+		fmt.Fprintln(stdout, "decision: allowed: not a cross-origin request")
+		return exitAllowed
+	default:
+		if err := corstest.Simulate(r, preflight, corstest.ResponseOf(w)); err != nil {
+			fmt.Fprintf(stdout, "decision: rejected: %v\n", err)
+			break
+		}
+		fmt.Fprintln(stdout, "decision: allowed")
+		return exitAllowed
+	}
+	return exitRejected
+}
+
+// probe compares the responses of the server at baseURL with the config, the exit code
+// is exitRejected if they differ.
+func probe(c cors.Config, baseURL, path string, origins []string, stdout, stderr io.Writer) int {
+	base, err := url.Parse(baseURL)
+	if err != nil || len(base.Host) == 0 {
+		fmt.Fprintf(stderr, "corscheck: bad probe url %q\n", baseURL)
+		return exitUsage
+	}
+	if len(origins) == 0 {
+		origins = probeOrigins(c)
+	}
+	p := &prober{
+		base:   base,
+		path:   path,
+		client: &http.Client{Timeout: 10 * time.Second},
+		sim:    newSimulator(c),
+	}
+	n, diffs, err := p.run(probeRequests(c, origins), stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "corscheck: %v\n", err)
+		return exitUsage
+	}
+	fmt.Fprintf(stdout, "probed %d requests, %d differences\n", n, diffs)
+	if diffs > 0 {
+		return exitRejected
+	}
+	return exitAllowed
+}
+
+// simulator runs the requests against the middleware and records why it aborts.
+type simulator struct {
+	engine *route.Engine
+	reason string
+}
+
+func newSimulator(c cors.Config) *simulator {
+	s := &simulator{engine: route.NewEngine(config.NewOptions([]config.Option{}))}
+	s.engine.Use(func(ctx context.Context, c *app.RequestContext) {
+		c.Next(ctx)
+		if err := c.Errors.Last(); err != nil && len(s.reason) == 0 {
+			s.reason = err.Error()
+		}
+	}, cors.New(c))
+	s.engine.Any("/*path", func(ctx context.Context, c *app.RequestContext) {
+		c.Status(consts.StatusOK)
+	})
+	return s
+}
+
+func (s *simulator) do(r *corstest.Request) *ut.ResponseRecorder {
+	return r.Do(s.engine)
+}
+
+// isSameOrigin reports whether the middleware sees the request as same-origin.
+func isSameOrigin(origin, host string) bool {
+	i := strings.Index(origin, "://")
+	return len(host) > 0 && i >= 0 && origin[i+3:] == host
+}
+
+// printResponse prints the status and the headers set by the middleware.
+func printResponse(out io.Writer, name string, w *ut.ResponseRecorder) {
+	fmt.Fprintf(out, "%s: %d\n", name, w.Code)
+	var lines []string
+	w.Header().VisitAll(func(key, value []byte) {
+		switch string(key) {
+		case "Content-Length", "Content-Type", "Date", "Server":
+			return
+		}
+		lines = append(lines, fmt.Sprintf("  %s: %s", key, value))
+	})
+	sort.Strings(lines)
+	for _, line := range lines {
+		fmt.Fprintln(out, line)
+	}
+}
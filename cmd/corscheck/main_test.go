@@ -0,0 +1,122 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+const testConfig = `{
+	"allow_origins": ["https://google.com"],
+	"allow_methods": ["GET", "PUT"],
+	"allow_headers": ["Content-Type", "X-Token"],
+	"expose_headers": ["X-Request-Id"],
+	"allow_credentials": true,
+	"max_age": "12h"
+}`
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "cors.json")
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func runCheck(t *testing.T, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestParseConfig(t *testing.T) {
+	c, err := parseConfig([]byte(testConfig))
+	assert.Nil(t, err)
+	assert.DeepEqual(t, []string{"https://google.com"}, c.AllowOrigins)
+	assert.DeepEqual(t, 12*time.Hour, c.MaxAge)
+	assert.True(t, c.AllowCredentials)
+
+	_, err = parseConfig([]byte(`{"allow_origin": ["https://google.com"]}`))
+	assert.NotNil(t, err)
+	_, err = parseConfig([]byte(`{"allow_origins": ["https://google.com"], "max_age": 3600}`))
+	assert.NotNil(t, err)
+	_, err = parseConfig([]byte(`{"allow_all_origins": true, "allow_origins": ["https://google.com"]}`))
+	assert.DeepEqual(t, "conflict settings: all origins are allowed. AllowOriginFunc or AllowOrigins is not needed", err.Error())
+}
+
+func TestRun(t *testing.T) {
+	path := writeConfig(t, testConfig)
+
+	code, out, _ := runCheck(t, "-config", path, "-origin", "https://google.com", "-method", "PUT",
+		"-header", "Content-Type: application/json", "-header", "X-Token: 1", "-credentials")
+	assert.DeepEqual(t, exitAllowed, code)
+	assert.DeepEqual(t, `preflight: 204
+  Access-Control-Allow-Credentials: true
+  Access-Control-Allow-Headers: Content-Type,X-Token
+  Access-Control-Allow-Methods: GET,PUT
+  Access-Control-Allow-Origin: https://google.com
+  Access-Control-Max-Age: 43200
+  Vary: Origin
+actual: 200
+  Access-Control-Allow-Credentials: true
+  Access-Control-Allow-Origin: https://google.com
+  Access-Control-Expose-Headers: X-Request-Id
+  Vary: Origin
+decision: allowed
+`, out)
+
+	code, out, _ = runCheck(t, "-config", path, "-origin", "https://google.com", "-method", "DELETE")
+	assert.DeepEqual(t, exitRejected, code)
+	assert.True(t, strings.HasSuffix(out, "decision: rejected: method DELETE is not in Access-Control-Allow-Methods \"GET,PUT\"\n"))
+
+	code, out, _ = runCheck(t, "-config", path, "-origin", "https://example.com")
+	assert.DeepEqual(t, exitRejected, code)
+	assert.DeepEqual(t, "actual: 403\ndecision: rejected: cors: origin not allowed\n", out)
+
+	code, out, _ = runCheck(t, "-config", path, "-origin", "https://example.com", "-host", "example.com", "-method", "PUT")
+	assert.DeepEqual(t, exitAllowed, code)
+	assert.DeepEqual(t, "actual: 200\ndecision: allowed: not a cross-origin request\n", out)
+}
+
+func TestRunUsage(t *testing.T) {
+	code, _, stderr := runCheck(t, "-origin", "https://google.com")
+	assert.DeepEqual(t, exitUsage, code)
+	assert.True(t, strings.HasPrefix(stderr, "usage: corscheck"))
+
+	code, _, stderr = runCheck(t, "-config", writeConfig(t, `{"max_age": "forever"}`))
+	assert.DeepEqual(t, exitUsage, code)
+	assert.True(t, strings.HasPrefix(stderr, "corscheck: "))
+
+	code, _, _ = runCheck(t, "-unknown")
+	assert.DeepEqual(t, exitUsage, code)
+}
@@ -0,0 +1,221 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/hertz-contrib/cors"
+	"github.com/hertz-contrib/cors/corstest"
+)
+
+// unlistedOrigin is probed to check that the server rejects unknown origins.
+const unlistedOrigin = "https://corscheck.invalid"
+
+// probedHeaders are the response headers compared by the probe.
+var probedHeaders = []string{
+	"Access-Control-Allow-Origin",
+	"Access-Control-Allow-Credentials",
+	"Access-Control-Allow-Methods",
+	"Access-Control-Allow-Headers",
+	"Access-Control-Expose-Headers",
+	"Access-Control-Max-Age",
+	"Timing-Allow-Origin",
+}
+
+// listHeaders are compared as case-insensitive sets.
+var listHeaders = map[string]bool{
+	"Access-Control-Allow-Methods":  true,
+	"Access-Control-Allow-Headers":  true,
+	"Access-Control-Expose-Headers": true,
+}
+
+// prober sends the requests to a running server and compares the responses with the
+// ones of the middleware built from the config.
+type prober struct {
+	base   *url.URL
+	path   string
+	client *http.Client
+	sim    *simulator
+}
+
+// probeOrigins returns the origins probed by default: the exact allowed and denied
+// origins, an unlisted one and null if the config allows it.
+func probeOrigins(c cors.Config) []string {
+	origins := []string{}
+	for _, o := range append(append([]string{}, c.AllowOrigins...), c.DenyOrigins...) {
+		if !strings.Contains(o, "*") {
+			origins = append(origins, strings.ToLower(o))
+		}
+	}
+	origins = append(origins, unlistedOrigin)
+	if c.AllowNullOrigin {
+		origins = append(origins, "null")
+	}
+	return origins
+}
+
+// probeRequests returns the matrix of requests. Actual requests are only sent with
+// safe methods so the probe never changes the state of the server, other methods are
+// checked with their preflight.
+func probeRequests(c cors.Config, origins []string) []*corstest.Request {
+	methods := mergeTokens([]string{"GET", "PUT"}, c.AllowMethods, strings.ToUpper)
+	headers := mergeTokens([]string{"X-Corscheck"}, c.AllowHeaders, http.CanonicalHeaderKey)
+	var requests []*corstest.Request
+	for _, origin := range origins {
+		requests = append(requests, corstest.Actual("GET", origin), corstest.Actual("HEAD", origin))
+		for _, method := range methods {
+			requests = append(requests, corstest.Preflight(origin, method))
+			for _, h := range headers {
+				requests = append(requests, corstest.Preflight(origin, method, h))
+			}
+		}
+	}
+	return requests
+}
+
+func mergeTokens(base, more []string, conv func(string) string) []string {
+	seen := map[string]bool{}
+	var tokens []string
+	for _, t := range append(append([]string{}, base...), more...) {
+		t = conv(strings.TrimSpace(t))
+		if len(t) > 0 && !seen[t] && t != "OPTIONS" {
+			seen[t] = true
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+// run sends the requests and prints the differences, it returns the number of
+// requests and of differences.
+func (p *prober) run(requests []*corstest.Request, out io.Writer) (int, int, error) {
+	diffs := 0
+	for _, r := range requests {
+		r.WithPath(p.path).WithHost(p.base.Host)
+		got, err := p.send(r)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, d := range compareResponses(p.sim.do(r), got) {
+			fmt.Fprintf(out, "%s: %s\n", describeRequest(r), d)
+			diffs++
+		}
+	}
+	return len(requests), diffs, nil
+}
+
+func (p *prober) send(r *corstest.Request) (*http.Response, error) {
+	u := *p.base
+	u.Path = strings.TrimSuffix(u.Path, "/") + r.Path
+	req, err := http.NewRequest(r.Method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Origin", r.Origin)
+	for _, h := range r.Headers {
+		req.Header.Set(h.Key, h.Value)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading the response to %s: %w", describeRequest(r), err)
+	}
+	return resp, nil
+}
+
+// compareResponses returns the differences between the expected and the actual response.
+func compareResponses(want *ut.ResponseRecorder, got *http.Response) []string {
+	var diffs []string
+	if isSuccess(want.Code) != isSuccess(got.StatusCode) {
+		diffs = append(diffs, fmt.Sprintf("status is %d, expected %d", got.StatusCode, want.Code))
+	}
+	for _, key := range probedHeaders {
+		w, g := want.Header().Get(key), got.Header.Get(key)
+		if listHeaders[key] {
+			w, g = normalizeList(w), normalizeList(g)
+		}
+		if w != g {
+			diffs = append(diffs, fmt.Sprintf("%s is %q, expected %q", key, g, w))
+		}
+	}
+	if hasToken(want.Header().Get("Vary"), "Origin") && !hasToken(strings.Join(got.Header.Values("Vary"), ","), "Origin") {
+		diffs = append(diffs, "Vary does not contain Origin")
+	}
+	return diffs
+}
+
+// isSuccess reports whether the status is a success, only the class of the status is
+// compared as the server may answer with the status of its own handlers.
+func isSuccess(code int) bool {
+	return code >= 200 && code < 300
+}
+
+func normalizeList(v string) string {
+	var tokens []string
+	for _, t := range strings.Split(v, ",") {
+		if t = strings.ToLower(strings.TrimSpace(t)); len(t) > 0 {
+			tokens = append(tokens, t)
+		}
+	}
+	sort.Strings(tokens)
+	return strings.Join(tokens, ",")
+}
+
+func hasToken(list, token string) bool {
+	for _, t := range strings.Split(list, ",") {
+		if strings.EqualFold(strings.TrimSpace(t), token) {
+			return true
+		}
+	}
+	return false
+}
+
+func describeRequest(r *corstest.Request) string {
+	var b strings.Builder
+	b.WriteString(r.Method)
+	for _, h := range r.Headers {
+		b.WriteString(" ")
+		b.WriteString(h.Key)
+		b.WriteString("=")
+		b.WriteString(h.Value)
+	}
+	b.WriteString(" from ")
+	b.WriteString(r.Origin)
+	return b.String()
+}
@@ -0,0 +1,122 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package main
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/network/standard"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/hertz-contrib/cors"
+)
+
+// startServer runs a Hertz server with the config on loopback and returns its base URL.
+func startServer(t *testing.T, c cors.Config) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	h := server.New(server.WithHostPorts(addr), server.WithTransport(standard.NewTransporter),
+		server.WithDisablePrintRoute(true), server.WithExitWaitTime(0))
+	h.Use(cors.New(c))
+	h.Any("/*path", func(ctx context.Context, c *app.RequestContext) {
+		c.String(consts.StatusOK, "ok")
+	})
+	go func() {
+		_ = h.Run()
+	}()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		// idle keepalive connections may make it time out
+		_ = h.Shutdown(ctx)
+	})
+	for i := 0; i < 100 && !h.IsRunning(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	for i := 0; i < 100; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return "http://" + addr
+}
+
+func TestProbeOrigins(t *testing.T) {
+	assert.DeepEqual(t, []string{"https://google.com", "https://evil.com", unlistedOrigin, "null"}, probeOrigins(cors.Config{
+		AllowOrigins:    []string{"https://Google.com", "https://*.google.com"},
+		DenyOrigins:     []string{"https://evil.com"},
+		AllowNullOrigin: true,
+	}))
+}
+
+func TestProbe(t *testing.T) {
+	path := writeConfig(t, testConfig)
+	c, err := loadConfig(path)
+	assert.Nil(t, err)
+
+	base := startServer(t, c)
+	code, out, stderr := runCheck(t, "-config", path, "-probe", base)
+	assert.DeepEqual(t, "", stderr)
+	assert.DeepEqual(t, exitAllowed, code)
+	assert.DeepEqual(t, "probed 20 requests, 0 differences\n", out)
+}
+
+func TestProbeDifferences(t *testing.T) {
+	path := writeConfig(t, testConfig)
+	c, err := loadConfig(path)
+	assert.Nil(t, err)
+	c.AllowOrigins = append(c.AllowOrigins, "https://corscheck.invalid")
+	c.AllowHeaders = []string{"Content-Type"}
+
+	base := startServer(t, c)
+	code, out, _ := runCheck(t, "-config", path, "-probe", base, "-probe-origin", "https://google.com",
+		"-probe-origin", "https://corscheck.invalid")
+	assert.DeepEqual(t, exitRejected, code)
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	assert.DeepEqual(t, "probed 20 requests, 64 differences", lines[len(lines)-1])
+	assert.True(t, strings.Contains(out, `GET from https://corscheck.invalid: status is 200, expected 403`))
+	assert.True(t, strings.Contains(out, `GET from https://corscheck.invalid: Access-Control-Allow-Origin is "https://corscheck.invalid", expected ""`))
+	assert.True(t, strings.Contains(out, `OPTIONS Access-Control-Request-Method=PUT from https://google.com: Access-Control-Allow-Headers is "content-type", expected "content-type,x-token"`))
+}
+
+func TestProbeBadURL(t *testing.T) {
+	code, _, stderr := runCheck(t, "-config", writeConfig(t, testConfig), "-probe", "localhost")
+	assert.DeepEqual(t, exitUsage, code)
+	assert.DeepEqual(t, "corscheck: bad probe url \"localhost\"\n", stderr)
+}
@@ -28,8 +28,8 @@
 package cors
 
 import (
-	"context"
 	"errors"
+	"regexp"
 	"strings"
 	"time"
 
@@ -42,6 +42,8 @@ type Config struct {
 
 	// AllowOrigins is a list of origins a cross-domain request can be executed from.
 	// If the special "*" value is present in the list, all origins will be allowed.
+	// An origin may have a port range like https://portal.example.com:8443-8450, or
+	// any port like https://portal.example.com:*.
 	// Default value is []
 	AllowOrigins []string
 
@@ -51,6 +53,10 @@ type Config struct {
 	// It is recommended to use AllowOriginFunc without setting AllowOrigins.
 	AllowOriginFunc func(origin string) bool
 
+	// AllowOriginFuncCache caches the decisions of AllowOriginFunc, see NewOriginCache.
+	// AllowOriginFunc is called on every request not matched by other rules if it is nil.
+	AllowOriginFuncCache *OriginCache
+
 	// AllowMethods is a list of methods the client is allowed to use with
 	// cross-domain requests. Default value is simple methods (GET and POST)
 	AllowMethods []string
@@ -67,21 +73,140 @@ type Config struct {
 	// API specification
 	ExposeHeaders []string
 
+	// DynamicExposeHeaders computes Access-Control-Expose-Headers after the handler runs,
+	// from the response headers actually present, in addition to ExposeHeaders.
+	// CORS-safelisted response headers and the headers set by the middleware, like Vary,
+	// are never listed.
+	DynamicExposeHeaders bool
+
+	// DynamicExposeAllow is a list of response headers that can be exposed dynamically.
+	// A header ending with "*" matches a prefix like X-RateLimit-*.
+	// All response headers can be exposed if it is empty.
+	DynamicExposeAllow []string
+
+	// DynamicExposeDeny is a list of response headers that are never exposed dynamically.
+	// It is checked before DynamicExposeAllow and supports the same prefix matching.
+	DynamicExposeDeny []string
+
 	// MaxAge indicates how long (in seconds) the results of a preflight request
 	// can be cached
 	MaxAge time.Duration
 
+	// PreflightHandlers runs the handler registered for OPTIONS on the route, and the
+	// middleware after this one, on allowed preflight requests, so they can extend the
+	// preflight response with AllowHeaders and SetMaxAge. The response is still a 204
+	// without body. Preflight requests are answered by this middleware alone if false.
+	PreflightHandlers bool
+
+	// PreflightOriginRateLimit is the number of preflight requests per second allowed for
+	// each origin, shared by all the clients of the origin. Preflight requests over the
+	// limit get 429 with Retry-After. Preflight requests are not limited per origin if it
+	// is zero.
+	PreflightOriginRateLimit float64
+
+	// PreflightOriginBurst is the number of preflight requests allowed at once for each
+	// origin. Default value is PreflightOriginRateLimit rounded up.
+	PreflightOriginBurst int
+
+	// PreflightClientRateLimit is the number of preflight requests per second allowed for
+	// each client IP, with the same response as PreflightOriginRateLimit. Preflight requests
+	// are not limited per client IP if it is zero.
+	PreflightClientRateLimit float64
+
+	// PreflightClientBurst is the number of preflight requests allowed at once for each
+	// client IP. Default value is PreflightClientRateLimit rounded up.
+	PreflightClientBurst int
+
+	// PreflightRateLimitSize is the number of origins and of client IPs tracked by the
+	// preflight rate limit, the least recently used are forgotten. Default value is 10000.
+	PreflightRateLimitSize int
+
 	// Allows to add origins like http://some-domain/*, https://api.* or http://some.*.subdomain.com
 	AllowWildcard bool
 
+	// AllowPublicSuffixWildcard allows wildcard rules matching every domain under a public
+	// suffix, like https://*.github.io or https://*.co.uk, which are rejected by Validate
+	// because anyone can register such a domain. Set it only if it is intended.
+	AllowPublicSuffixWildcard bool
+
 	// Allows usage of popular browser extensions schemas
 	AllowBrowserExtensions bool
 
-	// Allows usage of WebSocket protocol
+	// Allows usage of WebSocket protocol. The origin rules are also enforced on WebSocket
+	// handshakes, which are answered without CORS headers, see OriginChecker
 	AllowWebSockets bool
 
 	// Allows usage of file:// schema (dangerous!) use it only when you 100% sure it's needed
 	AllowFiles bool
+
+	// AllowLocalhost allows the http and https origins of localhost, 127.0.0.0/8 and [::1]
+	// on any port, for development servers. A warning is logged, never enable it in production.
+	AllowLocalhost bool
+
+	// AllowOriginCIDRs is a list of rules allowing the origins whose host is an IP address
+	// of a CIDR block, like 10.0.0.0/8, http://10.12.0.0/16:8080 or https://[fd00::/8]:8443-8450.
+	// The schema and the port or port range are optional.
+	AllowOriginCIDRs []string
+
+	// AllowTiming sends the Timing-Allow-Origin header on actual requests with the same
+	// value as Access-Control-Allow-Origin, so allowed origins can read resource timing.
+	AllowTiming bool
+
+	// TimingAllowOrigins is a list of origins that are given access to resource timing only.
+	// Their requests are not rejected, but they get no CORS headers.
+	TimingAllowOrigins []string
+
+	// DenyOrigins is a list of origins that are always rejected. It is checked before all
+	// allow rules, including AllowAllOrigins and AllowOriginFunc. An origin may contain
+	// one "*" to deny a group of origins like https://*.partner.com, whether or not
+	// AllowWildcard is set.
+	DenyOrigins []string
+
+	// DenyOriginPatterns is a list of regular expressions of origins that are always
+	// rejected, with the same precedence as DenyOrigins. They are case-insensitive, like
+	// the schemas and hosts of origins.
+	DenyOriginPatterns []string
+
+	// ResourceIsolation rejects cross-site requests based on the Fetch Metadata request
+	// headers Sec-Fetch-Site, Sec-Fetch-Mode and Sec-Fetch-Dest, including requests that
+	// never trigger CORS like a cross-site no-cors POST. Navigations, same-origin and
+	// same-site requests, and requests from origins allowed by this Config pass through.
+	ResourceIsolation bool
+
+	// ResourceIsolationAllowPaths is a list of paths that cross-site requests can always
+	// reach when ResourceIsolation is enabled.
+	ResourceIsolationAllowPaths []string
+
+	// CrossOriginOpenerPolicy is the value of the Cross-Origin-Opener-Policy header sent on
+	// every response, one of "unsafe-none", "same-origin-allow-popups", "same-origin" or
+	// "noopener-allow-popups". It is not sent if empty.
+	CrossOriginOpenerPolicy string
+
+	// CrossOriginEmbedderPolicy is the value of the Cross-Origin-Embedder-Policy header sent
+	// on every response, one of "unsafe-none", "require-corp" or "credentialless".
+	// It is not sent if empty.
+	CrossOriginEmbedderPolicy string
+
+	// CrossOriginResourcePolicy is the value of the Cross-Origin-Resource-Policy header sent
+	// on every response, one of "same-site", "same-origin" or "cross-origin".
+	// It is not sent if empty. Browsers only check it on no-cors requests, like images and
+	// scripts, so it does not restrict the origins allowed by the CORS rules.
+	CrossOriginResourcePolicy string
+
+	// AllowNullOrigin allows requests with the opaque "null" origin, as sent by sandboxed
+	// iframes, some redirects and file: pages, with its own credentials and max age rules.
+	// If this is not set, the null origin is allowed by AllowAllOrigins or AllowOriginFunc
+	// with the rules of the other origins, and can be rejected with DenyOrigins.
+	AllowNullOrigin bool
+
+	// AllowNullOriginCredentials indicates whether credentials are allowed for the null
+	// origin. AllowCredentials does not apply to the null origin.
+	AllowNullOriginCredentials bool
+
+	// NullOriginMaxAge indicates how long the results of a preflight request from the null
+	// origin can be cached. All opaque origins share the same cache key in the browser,
+	// so it is not cached by default.
+	NullOriginMaxAge time.Duration
 }
 
 // AddAllowMethods is allowed to add custom methods
@@ -123,31 +248,193 @@ func (c Config) validateAllowedSchemas(origin string) bool {
 	return false
 }
 
+var errAllOriginsDisabled = errors.New("conflict settings: all origins disabled")
+
 // Validate is check configuration of user defined.
 func (c Config) Validate() error {
 	if c.AllowAllOrigins && (c.AllowOriginFunc != nil || len(c.AllowOrigins) > 0) {
 		return errors.New("conflict settings: all origins are allowed. AllowOriginFunc or AllowOrigins is not needed")
 	}
-	if !c.AllowAllOrigins && c.AllowOriginFunc == nil && len(c.AllowOrigins) == 0 {
-		return errors.New("conflict settings: all origins disabled")
+	if c.AllowAllOrigins && (c.AllowLocalhost || len(c.AllowOriginCIDRs) > 0) {
+		return errors.New("conflict settings: all origins are allowed. AllowLocalhost or AllowOriginCIDRs is not needed")
+	}
+	if !c.AllowAllOrigins && c.AllowOriginFunc == nil && len(c.AllowOrigins) == 0 && !c.AllowNullOrigin && !c.AllowLocalhost &&
+		len(c.AllowOriginCIDRs) == 0 {
+		return errAllOriginsDisabled
+	}
+	if c.AllowOriginFunc == nil && c.AllowOriginFuncCache != nil {
+		return errors.New("conflict settings: AllowOriginFunc is not set. AllowOriginFuncCache is not needed")
+	}
+	if !c.AllowNullOrigin && (c.AllowNullOriginCredentials || c.NullOriginMaxAge > 0) {
+		return errors.New("conflict settings: null origin is not allowed. AllowNullOriginCredentials or NullOriginMaxAge is not needed")
 	}
 	for _, origin := range c.AllowOrigins {
+		if origin == nullOrigin {
+			return errors.New("bad origin: use AllowNullOrigin instead of adding \"null\" to AllowOrigins")
+		}
 		if !strings.Contains(origin, "*") && !c.validateAllowedSchemas(origin) {
 			return errors.New("bad origin: origins must contain '*' or include " + strings.Join(c.getAllowedSchemas(), ","))
 		}
+		if key, _, ok, err := parsePortRule(strings.ToLower(origin)); err != nil {
+			return errors.New("bad origin: " + err.Error())
+		} else if ok && !c.validateAllowedSchemas(key) {
+			return errors.New("bad origin: port rules must include " + strings.Join(c.getAllowedSchemas(), ","))
+		}
+		if err := c.validatePublicSuffixWildcard(origin); err != nil {
+			return err
+		}
+	}
+	if !c.AllowWildcard && c.AllowPublicSuffixWildcard {
+		return errors.New("conflict settings: wildcard is disabled. AllowPublicSuffixWildcard is not needed")
+	}
+	for _, rule := range c.AllowOriginCIDRs {
+		r, err := parseCIDRRule(rule)
+		if err != nil {
+			return errors.New("bad origin CIDR: " + err.Error())
+		}
+		if len(r.schema) > 0 && !contains(c.getAllowedSchemas(), r.schema) {
+			return errors.New("bad origin CIDR: schema must be one of " + strings.Join(c.getAllowedSchemas(), ","))
+		}
+	}
+	if c.PreflightOriginRateLimit < 0 || c.PreflightOriginBurst < 0 || c.PreflightClientRateLimit < 0 ||
+		c.PreflightClientBurst < 0 || c.PreflightRateLimitSize < 0 {
+		return errors.New("bad preflight rate limit: the rate limits, bursts and PreflightRateLimitSize must not be negative")
+	}
+	if c.PreflightOriginRateLimit == 0 && c.PreflightOriginBurst > 0 {
+		return errors.New("conflict settings: preflight origin rate limit is disabled. PreflightOriginBurst is not needed")
+	}
+	if c.PreflightClientRateLimit == 0 && c.PreflightClientBurst > 0 {
+		return errors.New("conflict settings: preflight client rate limit is disabled. PreflightClientBurst is not needed")
+	}
+	if c.PreflightOriginRateLimit == 0 && c.PreflightClientRateLimit == 0 && c.PreflightRateLimitSize > 0 {
+		return errors.New("conflict settings: preflight rate limit is disabled. PreflightRateLimitSize is not needed")
+	}
+	if !c.DynamicExposeHeaders && (len(c.DynamicExposeAllow) > 0 || len(c.DynamicExposeDeny) > 0) {
+		return errors.New("conflict settings: dynamic expose headers is disabled. DynamicExposeAllow or DynamicExposeDeny is not needed")
+	}
+	if !c.ResourceIsolation && len(c.ResourceIsolationAllowPaths) > 0 {
+		return errors.New("conflict settings: resource isolation is disabled. ResourceIsolationAllowPaths is not needed")
+	}
+	for _, origin := range c.TimingAllowOrigins {
+		if strings.Contains(origin, "*") || !c.validateAllowedSchemas(origin) {
+			return errors.New("bad timing origin: origins must not contain '*' and must include " + strings.Join(c.getAllowedSchemas(), ","))
+		}
+	}
+	if err := c.validateCrossOriginIsolation(); err != nil {
+		return err
+	}
+	for _, origin := range c.DenyOrigins {
+		if origin != nullOrigin && !strings.Contains(origin, "*") && !c.validateAllowedSchemas(origin) {
+			return errors.New("bad deny origin: origins must contain '*' or include " + strings.Join(c.getAllowedSchemas(), ","))
+		}
+		if strings.Count(origin, "*") > 1 {
+			return errors.New("bad deny origin: only one * is allowed")
+		}
+		if key, _, ok, err := parsePortRule(strings.ToLower(origin)); err != nil {
+			return errors.New("bad deny origin: " + err.Error())
+		} else if ok && !c.validateAllowedSchemas(key) {
+			return errors.New("bad deny origin: port rules must include " + strings.Join(c.getAllowedSchemas(), ","))
+		}
+	}
+	for _, pattern := range c.DenyOriginPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return errors.New("bad deny origin pattern: " + err.Error())
+		}
 	}
 	return nil
 }
 
-func (c Config) parseWildcardRules() [][]string {
-	var wRules [][]string
+var (
+	crossOriginOpenerPolicies   = []string{"unsafe-none", "same-origin-allow-popups", "same-origin", "noopener-allow-popups"}
+	crossOriginEmbedderPolicies = []string{"unsafe-none", "require-corp", "credentialless"}
+	crossOriginResourcePolicies = []string{"same-site", "same-origin", "cross-origin"}
+)
 
+// validatePublicSuffixWildcard rejects a wildcard rule matching every domain under a
+// public suffix, unless AllowPublicSuffixWildcard is set.
+func (c Config) validatePublicSuffixWildcard(origin string) error {
+	if !c.AllowWildcard || !strings.Contains(origin, "*") || isPortRule(origin) {
+		return nil
+	}
+	if strings.Count(origin, "*") > 1 {
+		return errors.New("bad origin: only one * is allowed")
+	}
+	if c.AllowPublicSuffixWildcard {
+		return nil
+	}
+	rules := parseWildcardOrigins(normalize([]string{origin}))
+	if suffix, ok := publicSuffixWildcard(rules[0]); ok && len(suffix) == 0 {
+		return errors.New("bad origin: " + origin + " matches every domain, set AllowPublicSuffixWildcard if it is intended")
+	} else if ok {
+		return errors.New("bad origin: " + origin + " matches every domain under the public suffix " + suffix +
+			", set AllowPublicSuffixWildcard if it is intended")
+	}
+	return nil
+}
+
+func (c Config) validateCrossOriginIsolation() error {
+	if c.CrossOriginOpenerPolicy != "" && !contains(crossOriginOpenerPolicies, c.CrossOriginOpenerPolicy) {
+		return errors.New("bad cross-origin opener policy: must be one of " + strings.Join(crossOriginOpenerPolicies, ","))
+	}
+	if c.CrossOriginEmbedderPolicy != "" && !contains(crossOriginEmbedderPolicies, c.CrossOriginEmbedderPolicy) {
+		return errors.New("bad cross-origin embedder policy: must be one of " + strings.Join(crossOriginEmbedderPolicies, ","))
+	}
+	if c.CrossOriginResourcePolicy != "" && !contains(crossOriginResourcePolicies, c.CrossOriginResourcePolicy) {
+		return errors.New("bad cross-origin resource policy: must be one of " + strings.Join(crossOriginResourcePolicies, ","))
+	}
+	return nil
+}
+
+func (c Config) parseWildcardRules() [][]string {
 	if !c.AllowWildcard {
-		return wRules
+		return nil
 	}
+	return parseWildcardOrigins(c.AllowOrigins)
+}
 
-	for _, o := range c.AllowOrigins {
-		if !strings.Contains(o, "*") {
+func (c Config) parseCIDRRules() []cidrRule {
+	var rules []cidrRule
+	for _, rule := range c.AllowOriginCIDRs {
+		if r, err := parseCIDRRule(rule); err == nil {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+func (c Config) parseDenyWildcardRules() [][]string {
+	return parseWildcardOrigins(normalize(c.DenyOrigins))
+}
+
+func (c Config) parseDenyPatterns() []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0, len(c.DenyOriginPatterns))
+	for _, p := range c.DenyOriginPatterns {
+		patterns = append(patterns, regexp.MustCompile("(?i)"+p))
+	}
+	return patterns
+}
+
+func (c Config) parseTimingAllowOrigins() map[string]struct{} {
+	origins := make(map[string]struct{}, len(c.TimingAllowOrigins))
+	for _, o := range normalize(c.TimingAllowOrigins) {
+		origins[o] = struct{}{}
+	}
+	return origins
+}
+
+func (c Config) parseIsolationAllowPaths() map[string]struct{} {
+	paths := make(map[string]struct{}, len(c.ResourceIsolationAllowPaths))
+	for _, p := range c.ResourceIsolationAllowPaths {
+		paths[p] = struct{}{}
+	}
+	return paths
+}
+
+func parseWildcardOrigins(origins []string) [][]string {
+	var wRules [][]string
+
+	for _, o := range origins {
+		if !strings.Contains(o, "*") || isPortRule(o) {
 			continue
 		}
 
@@ -190,8 +477,5 @@ func Default() app.HandlerFunc {
 
 // New returns the location middleware with user-defined custom configuration.
 func New(config Config) app.HandlerFunc {
-	cors := newCors(config)
-	return func(ctx context.Context, c *app.RequestContext) {
-		cors.applyCors(c)
-	}
+	return newCors(config).handle
 }
@@ -0,0 +1,335 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/hertz-contrib/cors/corstest"
+)
+
+// conformanceCase is a request run against New and the outcome expected by the Fetch
+// CORS protocol, see https://fetch.spec.whatwg.org/#http-cors-protocol.
+type conformanceCase struct {
+	name    string
+	config  Config
+	request *corstest.Request
+	// credentials is the credentials mode of the request, true for "include"
+	credentials bool
+	// check returns an error if the response does not conform
+	check func(w *ut.ResponseRecorder, c *conformanceCase) error
+	// deviation explains why the middleware is known not to conform, the case is then an
+	// expected failure and fixing it is reported
+	deviation string
+}
+
+const conformanceOrigin = "https://app.example"
+
+func conformanceConfig() Config {
+	return Config{
+		AllowOrigins:  []string{conformanceOrigin},
+		AllowMethods:  []string{"GET", "POST", "PUT"},
+		AllowHeaders:  []string{"Content-Type", "X-Token"},
+		ExposeHeaders: []string{"X-Total"},
+		MaxAge:        time.Hour,
+	}
+}
+
+func withConformance(f func(c *Config)) Config {
+	c := conformanceConfig()
+	f(&c)
+	return c
+}
+
+func newConformanceRouter(c Config) *route.Engine {
+	router := route.NewEngine(config.NewOptions([]config.Option{}))
+	router.Use(New(c))
+	router.Any("/", func(ctx context.Context, c *app.RequestContext) {
+		c.String(consts.StatusOK, "ok")
+	})
+	router.GET("/redirect", func(ctx context.Context, c *app.RequestContext) {
+		c.Redirect(consts.StatusFound, []byte("/"))
+	})
+	return router
+}
+
+// conformanceCases lists the cases of the suite, modeled on the web platform tests of
+// CORS and CORS-preflight.
+var conformanceCases = []conformanceCase{
+	{
+		name:    "simple GET from an allowed origin passes the CORS check",
+		config:  conformanceConfig(),
+		request: corstest.Actual("GET", conformanceOrigin),
+		check:   expectCorsPass,
+	},
+	{
+		name:    "simple POST from another origin fails the CORS check",
+		config:  conformanceConfig(),
+		request: corstest.Actual("POST", "https://other.example"),
+		check:   expectCorsFail,
+	},
+	{
+		name: "wildcard origin passes the CORS check without credentials",
+		config: Config{
+			AllowAllOrigins: true,
+		},
+		request: corstest.Actual("GET", conformanceOrigin),
+		check:   expectCorsPass,
+	},
+	{
+		name: "wildcard origin with credentials passes the CORS check of a credentialed request",
+		config: Config{
+			AllowAllOrigins:  true,
+			AllowCredentials: true,
+		},
+		request:     corstest.Actual("GET", conformanceOrigin),
+		credentials: true,
+		check:       expectCorsPass,
+		deviation:   "Access-Control-Allow-Origin is * with credentials instead of the reflected origin",
+	},
+	{
+		name:        "credentialed request passes the CORS check",
+		config:      withConformance(func(c *Config) { c.AllowCredentials = true }),
+		request:     corstest.Actual("GET", conformanceOrigin),
+		credentials: true,
+		check:       expectCorsPass,
+	},
+	{
+		name:        "credentialed request fails the CORS check without AllowCredentials",
+		config:      conformanceConfig(),
+		request:     corstest.Actual("GET", conformanceOrigin),
+		credentials: true,
+		check:       expectCorsFail,
+	},
+	{
+		name:    "reflected origin is varied on Origin",
+		config:  conformanceConfig(),
+		request: corstest.Actual("GET", conformanceOrigin),
+		check:   expectVaryOrigin,
+	},
+	{
+		name:      "rejected origin is varied on Origin",
+		config:    conformanceConfig(),
+		request:   corstest.Actual("GET", "https://other.example"),
+		check:     expectVaryOrigin,
+		deviation: "rejected responses have no Vary: Origin, a shared cache may serve them to allowed origins",
+	},
+	{
+		name:    "exposed headers are listed on actual responses",
+		config:  conformanceConfig(),
+		request: corstest.Actual("GET", conformanceOrigin),
+		check:   expectHeader("Access-Control-Expose-Headers", "X-Total"),
+	},
+	{
+		name:    "preflight for a safelisted method passes",
+		config:  conformanceConfig(),
+		request: corstest.Preflight(conformanceOrigin, "POST"),
+		check:   expectPreflightPass("POST"),
+	},
+	{
+		name:    "preflight for an allowed method passes",
+		config:  conformanceConfig(),
+		request: corstest.Preflight(conformanceOrigin, "PUT"),
+		check:   expectPreflightPass("PUT"),
+	},
+	{
+		name:    "preflight for another method fails",
+		config:  conformanceConfig(),
+		request: corstest.Preflight(conformanceOrigin, "DELETE"),
+		check:   expectPreflightFail("DELETE"),
+	},
+	{
+		name:    "preflight for allowed headers passes, header names are case-insensitive",
+		config:  conformanceConfig(),
+		request: corstest.Preflight(conformanceOrigin, "PUT", "x-token", "content-type"),
+		check:   expectPreflightPass("PUT", "x-token", "content-type"),
+	},
+	{
+		name:    "preflight for another header fails",
+		config:  conformanceConfig(),
+		request: corstest.Preflight(conformanceOrigin, "PUT", "X-Other"),
+		check:   expectPreflightFail("PUT", "X-Other"),
+	},
+	{
+		name:    "preflight from another origin fails",
+		config:  conformanceConfig(),
+		request: corstest.Preflight("https://other.example", "GET"),
+		check:   expectPreflightFail("GET"),
+	},
+	{
+		name:        "credentialed preflight passes",
+		config:      withConformance(func(c *Config) { c.AllowCredentials = true }),
+		request:     corstest.Preflight(conformanceOrigin, "PUT", "X-Token"),
+		credentials: true,
+		check:       expectPreflightPass("PUT", "X-Token"),
+	},
+	{
+		name: "wildcard allowed headers do not cover Authorization",
+		config: Config{
+			AllowAllOrigins: true,
+			AllowHeaders:    []string{"*"},
+		},
+		request: corstest.Preflight(conformanceOrigin, "GET", "Authorization"),
+		check:   expectPreflightFail("GET", "Authorization"),
+	},
+	{
+		name:    "preflight is cached for Max-Age seconds",
+		config:  conformanceConfig(),
+		request: corstest.Preflight(conformanceOrigin, "PUT"),
+		check:   expectHeader("Access-Control-Max-Age", "3600"),
+	},
+	{
+		name:    "OPTIONS request without Access-Control-Request-Method is not a preflight",
+		config:  conformanceConfig(),
+		request: corstest.Actual("OPTIONS", conformanceOrigin),
+		check: func(w *ut.ResponseRecorder, c *conformanceCase) error {
+			if w.Body.String() != "ok" {
+				return fmt.Errorf("handler did not run, got status %d", w.Code)
+			}
+			return expectCorsPass(w, c)
+		},
+		deviation: "every OPTIONS request with an Origin is answered as a preflight",
+	},
+	{
+		name:    "null origin passes the CORS check when allowed",
+		config:  withConformance(func(c *Config) { c.AllowNullOrigin = true }),
+		request: corstest.Actual("GET", "null"),
+		check:   expectCorsPass,
+	},
+	{
+		name:    "null origin fails the CORS check by default",
+		config:  conformanceConfig(),
+		request: corstest.Actual("GET", "null"),
+		check:   expectCorsFail,
+	},
+	{
+		name:    "redirect responses pass the CORS check",
+		config:  conformanceConfig(),
+		request: corstest.Actual("GET", conformanceOrigin).WithPath("/redirect"),
+		check: func(w *ut.ResponseRecorder, c *conformanceCase) error {
+			if w.Code != consts.StatusFound {
+				return fmt.Errorf("expected a redirect, got status %d", w.Code)
+			}
+			return expectCorsPass(w, c)
+		},
+	},
+	{
+		name:    "preflight is not redirected",
+		config:  conformanceConfig(),
+		request: corstest.Preflight(conformanceOrigin, "GET").WithPath("/redirect"),
+		check:   expectPreflightPass("GET"),
+	},
+}
+
+func TestFetchConformance(t *testing.T) {
+	for i := range conformanceCases {
+		c := &conformanceCases[i]
+		t.Run(c.name, func(t *testing.T) {
+			w := c.request.Do(newConformanceRouter(c.config))
+			err := c.check(w, c)
+			switch {
+			case len(c.deviation) == 0 && err != nil:
+				t.Errorf("does not conform: %v", err)
+			case len(c.deviation) > 0 && err == nil:
+				t.Errorf("known deviation %q is fixed, remove it from the suite", c.deviation)
+			case len(c.deviation) > 0:
+				t.Logf("expected failure, %s: %v", c.deviation, err)
+			}
+		})
+	}
+}
+
+func expectCorsPass(w *ut.ResponseRecorder, c *conformanceCase) error {
+	return corstest.CORSCheck(c.request.Origin, c.credentials, w.Header())
+}
+
+func expectCorsFail(w *ut.ResponseRecorder, c *conformanceCase) error {
+	if corstest.CORSCheck(c.request.Origin, c.credentials, w.Header()) == nil {
+		return errors.New("CORS check passed")
+	}
+	return nil
+}
+
+func expectPreflightPass(method string, headers ...string) func(w *ut.ResponseRecorder, c *conformanceCase) error {
+	return func(w *ut.ResponseRecorder, c *conformanceCase) error {
+		return corstest.PreflightCheck(browserRequest(c, method, headers), corstest.ResponseOf(w))
+	}
+}
+
+func expectPreflightFail(method string, headers ...string) func(w *ut.ResponseRecorder, c *conformanceCase) error {
+	return func(w *ut.ResponseRecorder, c *conformanceCase) error {
+		if corstest.PreflightCheck(browserRequest(c, method, headers), corstest.ResponseOf(w)) == nil {
+			return errors.New("CORS-preflight passed")
+		}
+		return nil
+	}
+}
+
+// browserRequest is the request of the case as made by a script, every header has a
+// value that is not CORS-safelisted.
+func browserRequest(c *conformanceCase, method string, headers []string) *corstest.BrowserRequest {
+	r := &corstest.BrowserRequest{
+		Mode:        corstest.ModeCors,
+		Credentials: c.credentials,
+		Origin:      c.request.Origin,
+		Method:      method,
+		Headers:     make(map[string]string, len(headers)),
+	}
+	for _, h := range headers {
+		r.Headers[h] = "unsafe"
+	}
+	return r
+}
+
+func expectVaryOrigin(w *ut.ResponseRecorder, c *conformanceCase) error {
+	for _, v := range strings.Split(w.Header().Get("Vary"), ",") {
+		if strings.EqualFold(strings.TrimSpace(v), "Origin") {
+			return nil
+		}
+	}
+	return fmt.Errorf("Vary is %q", w.Header().Get("Vary"))
+}
+
+func expectHeader(key, value string) func(w *ut.ResponseRecorder, c *conformanceCase) error {
+	return func(w *ut.ResponseRecorder, c *conformanceCase) error {
+		if v := w.Header().Get(key); v != value {
+			return fmt.Errorf("%s is %q, expected %q", key, v, value)
+		}
+		return nil
+	}
+}
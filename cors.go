@@ -29,22 +29,73 @@ package cors
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"regexp"
 	"strings"
 
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 )
 
 type cors struct {
-	allowAllOrigins  bool
-	allowCredentials bool
-	allowOriginFunc  func(string) bool
-	allowOrigins     []string
-	normalHeaders    map[string]string
-	preflightHeaders map[string]string
-	wildcardOrigins  [][]string
+	allowAllOrigins      bool
+	allowCIDRs           []cidrRule
+	allowCredentials     bool
+	allowLocalhost       bool
+	allowNullOrigin      bool
+	preflightHandlers    bool
+	allowOriginFunc      func(string) bool
+	allowOriginCache     *OriginCache
+	allowOrigins         *originMatcher
+	allowTiming          bool
+	crossOriginHeaders   []header
+	denyOrigins          *originMatcher
+	denyOriginPatterns   []*regexp.Regexp
+	isolation            bool
+	isolationAllowPaths  map[string]struct{}
+	dynamicExpose        bool
+	dynamicExposeAllow   []string
+	dynamicExposeDeny    []string
+	normalHeaders        []header
+	preflightHeaders     []header
+	nullNormalHeaders    []header
+	nullPreflightHeaders []header
+	preflightLimiter     *preflightLimiter
+	timingAllowOrigins   map[string]struct{}
+	timingOnlyHeader     []byte
+	webSockets           bool
 }
 
+// Errors attached to the request context when a cross-origin request is rejected.
+var (
+	ErrOriginNotAllowed     = errors.New("cors: origin not allowed")
+	ErrOriginDenied         = errors.New("cors: origin denied")
+	ErrNullOriginNotAllowed = errors.New("cors: null origin not allowed")
+)
+
+// corsRequest is the kind of request handled by applyCors.
+type corsRequest int
+
+const (
+	// notCorsRequest is a same-origin or rejected request, or a request without Origin.
+	notCorsRequest corsRequest = iota
+	// actualRequest is an allowed actual cross-origin request.
+	actualRequest
+	// preflightRequest is an allowed preflight request.
+	preflightRequest
+)
+
+// nullOrigin is the serialization of an opaque origin.
+const nullOrigin = "null"
+
+var (
+	headerAllowOrigin       = []byte("Access-Control-Allow-Origin")
+	headerTimingAllowOrigin = []byte("Timing-Allow-Origin")
+	nullOriginBytes         = []byte(nullOrigin)
+)
+
 var (
 	DefaultHeaderBytes = [][]byte{
 		[]byte("OPTIONS"),
@@ -78,7 +129,15 @@ func newCors(config Config) *cors {
 	if err := config.Validate(); err != nil {
 		panic(err.Error())
 	}
+	return compileCors(config)
+}
 
+// compileCors compiles a valid config.
+func compileCors(config Config) *cors {
+	if config.AllowLocalhost {
+		hlog.Warn("cors: AllowLocalhost is enabled, any page served from localhost on any port " +
+			"can make cross-origin requests with the allowed credentials. It is for development only, never enable it in production!")
+	}
 	for _, origin := range config.AllowOrigins {
 		if origin == "*" {
 			config.AllowAllOrigins = true
@@ -86,55 +145,143 @@ func newCors(config Config) *cors {
 	}
 
 	return &cors{
-		allowOriginFunc:  config.AllowOriginFunc,
-		allowAllOrigins:  config.AllowAllOrigins,
-		allowCredentials: config.AllowCredentials,
-		allowOrigins:     normalize(config.AllowOrigins),
-		normalHeaders:    generateNormalHeaders(config),
-		preflightHeaders: generatePreflightHeaders(config),
-		wildcardOrigins:  config.parseWildcardRules(),
+		allowOriginFunc:      config.AllowOriginFunc,
+		allowOriginCache:     config.AllowOriginFuncCache,
+		allowAllOrigins:      config.AllowAllOrigins,
+		allowCIDRs:           config.parseCIDRRules(),
+		allowCredentials:     config.AllowCredentials,
+		allowLocalhost:       config.AllowLocalhost,
+		allowNullOrigin:      config.AllowNullOrigin,
+		preflightHandlers:    config.PreflightHandlers,
+		allowOrigins:         newOriginMatcher(normalize(config.AllowOrigins), config.parseWildcardRules()),
+		allowTiming:          config.AllowTiming,
+		denyOrigins:          newOriginMatcher(normalize(config.DenyOrigins), config.parseDenyWildcardRules()),
+		denyOriginPatterns:   config.parseDenyPatterns(),
+		isolation:            config.ResourceIsolation,
+		crossOriginHeaders:   compileHeaders(generateCrossOriginIsolationHeaders(config)),
+		isolationAllowPaths:  config.parseIsolationAllowPaths(),
+		dynamicExpose:        config.DynamicExposeHeaders,
+		dynamicExposeAllow:   normalize(config.DynamicExposeAllow),
+		dynamicExposeDeny:    normalize(config.DynamicExposeDeny),
+		normalHeaders:        compileHeaders(generateNormalHeaders(config)),
+		preflightHeaders:     compileHeaders(generatePreflightHeaders(config)),
+		nullNormalHeaders:    compileHeaders(generateNullOriginNormalHeaders(config)),
+		nullPreflightHeaders: compileHeaders(generateNullOriginPreflightHeaders(config)),
+		preflightLimiter:     newPreflightLimiter(config),
+		timingAllowOrigins:   config.parseTimingAllowOrigins(),
+		timingOnlyHeader:     []byte(strings.Join(normalize(config.TimingAllowOrigins), ", ")),
+		webSockets:           config.AllowWebSockets,
 	}
 }
 
-func (cors *cors) applyCors(c *app.RequestContext) {
-	origin := c.Request.Header.Get("Origin")
-	if len(origin) == 0 {
-		// request is not a CORS request
-		return
+// handle is the middleware of the policy.
+func (cors *cors) handle(ctx context.Context, c *app.RequestContext) {
+	switch cors.applyCors(c) {
+	case actualRequest:
+		c.Next(ctx)
+		cors.handleAfter(c)
+	case preflightRequest:
+		// Only a handler registered for OPTIONS on this route runs, to extend the preflight
+		if cors.preflightHandlers && len(c.FullPath()) > 0 {
+			c.Next(ctx)
+			cors.handlePreflightAfter(c)
+			c.Response.ResetBody()
+		}
+		c.AbortWithStatus(consts.StatusNoContent) // Using 204 is better than 200 when the request status is OPTIONS
 	}
-	host := c.Request.Host()
+}
 
-	o := str2bytes(origin)
-	if bytes.HasPrefix(o, DefaultSchemasBytes[0]) {
-		if compareByteSlices(o, DefaultSchemasBytes[0], host) == 0 {
-			return
+// applyCors writes the CORS headers of the request. It reports whether the request is
+// an allowed actual or preflight cross-origin request, whose headers may still be
+// extended after the handler runs.
+func (cors *cors) applyCors(c *app.RequestContext) corsRequest {
+	setHeaders(c, cors.crossOriginHeaders)
+	if cors.isolation && !cors.allowResourceIsolation(c) {
+		_ = c.AbortWithError(consts.StatusForbidden, ErrCrossSiteRequest)
+		return notCorsRequest
+	}
+	if cors.webSockets && isWebSocketUpgrade(c) {
+		// WebSocket handshakes are not covered by CORS, only the origin rules apply
+		if err := cors.checkWebSocketOrigin(c); err != nil {
+			_ = c.AbortWithError(consts.StatusForbidden, err)
 		}
+		return notCorsRequest
+	}
+
+	o := c.Request.Header.Peek("Origin")
+	if len(o) == 0 {
+		// request is not a CORS request
+		cors.handleTimingOnly(c)
+		return notCorsRequest
 	}
-	if bytes.HasPrefix(o, DefaultSchemasBytes[1]) {
-		if compareByteSlices(o, DefaultSchemasBytes[1], host) == 0 {
-			return
+	if isSameOrigin(o, c.Request.Host()) {
+		return notCorsRequest
+	}
+	// origin is only valid until the request is released, it must be copied to be retained
+	origin := bytes2str(o)
+
+	if err := cors.checkOrigin(origin); err != nil {
+		if _, ok := cors.timingAllowOrigins[origin]; ok && err == ErrOriginNotAllowed {
+			// origin has access to resource timing only
+			cors.handleTimingOnly(c)
+			return notCorsRequest
 		}
+		_ = c.AbortWithError(consts.StatusForbidden, err)
+		return notCorsRequest
+	}
+
+	if cors.preflightLimiter != nil && bytes.Equal(c.Request.Method(), DefaultHeaderBytes[0]) && !cors.allowPreflight(c, origin) {
+		return notCorsRequest
 	}
 
-	if !cors.validateOrigin(origin) {
-		c.AbortWithStatus(consts.StatusForbidden)
-		return
+	if origin == nullOrigin && cors.allowNullOrigin {
+		return cors.handleNullOrigin(c)
 	}
 
+	request := actualRequest
 	if bytes.Equal(c.Request.Method(), DefaultHeaderBytes[0]) {
+		request = preflightRequest
 		cors.handlePreflight(c)
-		defer c.AbortWithStatus(consts.StatusNoContent) // Using 204 is better than 200 when the request status is OPTIONS
 	} else {
 		cors.handleNormal(c)
+		if cors.allowTiming && !cors.allowAllOrigins {
+			c.Response.Header.SetCanonical(headerTimingAllowOrigin, o)
+		}
 	}
 
 	if !cors.allowAllOrigins {
-		c.Header("Access-Control-Allow-Origin", origin)
+		c.Response.Header.SetCanonical(headerAllowOrigin, o)
+	}
+	return request
+}
+
+// handleTimingOnly gives the origins of TimingAllowOrigins access to resource timing,
+// without any CORS headers.
+func (cors *cors) handleTimingOnly(c *app.RequestContext) {
+	if len(cors.timingOnlyHeader) > 0 {
+		c.Response.Header.SetCanonical(headerTimingAllowOrigin, cors.timingOnlyHeader)
+	}
+}
+
+// handleNullOrigin answers a request from the opaque null origin. It never uses the
+// wildcard, because "*" would also grant access to every other origin.
+func (cors *cors) handleNullOrigin(c *app.RequestContext) corsRequest {
+	request := actualRequest
+	if bytes.Equal(c.Request.Method(), DefaultHeaderBytes[0]) {
+		request = preflightRequest
+		setHeaders(c, cors.nullPreflightHeaders)
+	} else {
+		setHeaders(c, cors.nullNormalHeaders)
+		if cors.allowTiming {
+			c.Response.Header.SetCanonical(headerTimingAllowOrigin, nullOriginBytes)
+		}
 	}
+	c.Response.Header.SetCanonical(headerAllowOrigin, nullOriginBytes)
+	return request
 }
 
-func (cors *cors) validateWildcardOrigin(origin string) bool {
-	for _, w := range cors.wildcardOrigins {
+func matchWildcardOrigin(wildcardOrigins [][]string, origin string) bool {
+	for _, w := range wildcardOrigins {
 		if w[0] == "*" && strings.HasSuffix(origin, w[1]) {
 			return true
 		}
@@ -150,35 +297,75 @@ func (cors *cors) validateWildcardOrigin(origin string) bool {
 }
 
 func (cors *cors) validateOrigin(origin string) bool {
-	if cors.allowAllOrigins {
-		return true
+	return cors.checkOrigin(origin) == nil
+}
+
+// checkOrigin returns the reason why the origin is rejected, or nil if it is allowed.
+func (cors *cors) checkOrigin(origin string) error {
+	if cors.isDeniedOrigin(origin) {
+		return ErrOriginDenied
 	}
-	for _, value := range cors.allowOrigins {
-		if value == origin {
-			return true
+	if origin == nullOrigin {
+		// without AllowNullOrigin, the null origin is allowed like the other origins
+		if cors.allowNullOrigin || cors.allowAllOrigins {
+			return nil
+		}
+		if cors.allowOriginFunc != nil && cors.allowOriginByFunc(origin) {
+			return nil
 		}
+		return ErrNullOriginNotAllowed
+	}
+	if cors.allowAllOrigins {
+		return nil
+	}
+	if cors.allowOrigins.match(origin) {
+		return nil
+	}
+	if cors.allowLocalhost && isLocalhostOrigin(origin) {
+		return nil
+	}
+	if len(cors.allowCIDRs) > 0 && cors.matchCIDR(origin) {
+		return nil
 	}
-	if len(cors.wildcardOrigins) > 0 && cors.validateWildcardOrigin(origin) {
+	if cors.allowOriginFunc != nil && cors.allowOriginByFunc(origin) {
+		return nil
+	}
+	return ErrOriginNotAllowed
+}
+
+func (cors *cors) allowOriginByFunc(origin string) bool {
+	if cors.allowOriginCache != nil {
+		return cors.allowOriginCache.allow(origin, cors.allowOriginFunc)
+	}
+	// origin may point into the request, the function could retain it
+	return cors.allowOriginFunc(string(str2bytes(origin)))
+}
+
+func (cors *cors) isDeniedOrigin(origin string) bool {
+	if cors.denyOrigins.empty() && len(cors.denyOriginPatterns) == 0 {
+		return false
+	}
+	if cors.denyOrigins.match(strings.ToLower(origin)) {
 		return true
 	}
-	if cors.allowOriginFunc != nil {
-		return cors.allowOriginFunc(origin)
+	for _, pattern := range cors.denyOriginPatterns {
+		if pattern.MatchString(origin) {
+			return true
+		}
 	}
 	return false
 }
 
 func (cors *cors) handlePreflight(c *app.RequestContext) {
-	for key, value := range cors.preflightHeaders {
-		if len(value) > 0 {
-			c.Response.Header.Set(key, value)
-		}
-	}
+	setHeaders(c, cors.preflightHeaders)
 }
 
 func (cors *cors) handleNormal(c *app.RequestContext) {
-	for key, value := range cors.normalHeaders {
-		if len(value) > 0 {
-			c.Response.Header.Set(key, value)
-		}
+	setHeaders(c, cors.normalHeaders)
+}
+
+func setHeaders(c *app.RequestContext, headers []header) {
+	for i := range headers {
+		c.Response.Header.SetCanonical(headers[i].key, headers[i].value)
 	}
 }
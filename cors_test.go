@@ -58,7 +58,11 @@ func newTestRouter(c Config) *route.Engine {
 }
 
 func performRequest(r *route.Engine, method, origin string, headers ...ut.Header) *ut.ResponseRecorder {
-	url := "/"
+	return performRequestPath(r, method, "/", origin, headers...)
+}
+
+func performRequestPath(r *route.Engine, method, path, origin string, headers ...ut.Header) *ut.ResponseRecorder {
+	url := path
 	for _, h := range headers {
 		if h.Key == "Host" {
 			url = DefaultSchemas[0] + h.Value + url
@@ -109,6 +113,47 @@ func TestBadConfig(t *testing.T) {
 			AllowOrigins: []string{"google.com"},
 		})
 	})
+	assert.Panic(t, func() {
+		New(Config{
+			AllowOrigins: []string{"null"},
+		})
+	})
+	assert.Panic(t, func() {
+		New(Config{
+			AllowAllOrigins:            true,
+			AllowNullOriginCredentials: true,
+		})
+	})
+	assert.Panic(t, func() {
+		New(Config{
+			AllowAllOrigins: true,
+			DenyOrigins:     []string{"evil.com"},
+		})
+	})
+	assert.Panic(t, func() {
+		New(Config{
+			AllowAllOrigins: true,
+			DenyOrigins:     []string{"https://*.evil.*"},
+		})
+	})
+	assert.Panic(t, func() {
+		New(Config{
+			AllowAllOrigins:    true,
+			DenyOriginPatterns: []string{"https://(evil"},
+		})
+	})
+	assert.Panic(t, func() {
+		New(Config{
+			AllowAllOrigins:           true,
+			CrossOriginEmbedderPolicy: "require-cors",
+		})
+	})
+	assert.Panic(t, func() {
+		New(Config{
+			AllowAllOrigins:           true,
+			CrossOriginResourcePolicy: "same-host",
+		})
+	})
 }
 
 func TestNormalize(t *testing.T) {
@@ -217,6 +262,68 @@ func TestGeneratePreflightHeaders_MaxAge(t *testing.T) {
 	assert.DeepEqual(t, 2, len(header))
 }
 
+func TestGenerateCrossOriginIsolationHeaders(t *testing.T) {
+	header := generateCrossOriginIsolationHeaders(Config{})
+	assert.DeepEqual(t, 0, len(header))
+
+	header = generateCrossOriginIsolationHeaders(Config{
+		CrossOriginOpenerPolicy:   "same-origin",
+		CrossOriginEmbedderPolicy: "require-corp",
+		CrossOriginResourcePolicy: "same-site",
+	})
+	assert.DeepEqual(t, header["Cross-Origin-Opener-Policy"], "same-origin")
+	assert.DeepEqual(t, header["Cross-Origin-Embedder-Policy"], "require-corp")
+	assert.DeepEqual(t, header["Cross-Origin-Resource-Policy"], "same-site")
+	assert.DeepEqual(t, 3, len(header))
+}
+
+func TestCrossOriginResourcePolicyWithCors(t *testing.T) {
+	// CORP only applies to no-cors requests, it does not conflict with the CORS rules
+	assert.Nil(t, Config{AllowAllOrigins: true, CrossOriginResourcePolicy: "same-origin"}.Validate())
+	assert.Nil(t, Config{
+		AllowOrigins:              []string{"https://google.com"},
+		ResourceIsolation:         true,
+		CrossOriginResourcePolicy: "cross-origin",
+	}.Validate())
+
+	router := newTestRouter(Config{
+		AllowAllOrigins:           true,
+		CrossOriginResourcePolicy: "same-origin",
+	})
+	w := performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, "get", w.Body.String())
+	assert.DeepEqual(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "same-origin", w.Header().Get("Cross-Origin-Resource-Policy"))
+}
+
+func TestGenerateNullOriginHeaders(t *testing.T) {
+	config := Config{
+		AllowAllOrigins:            true,
+		AllowCredentials:           true,
+		AllowNullOrigin:            true,
+		AllowNullOriginCredentials: false,
+		MaxAge:                     12 * time.Hour,
+	}
+	header := generateNullOriginNormalHeaders(config)
+	assert.DeepEqual(t, header["Access-Control-Allow-Origin"], "")
+	assert.DeepEqual(t, header["Access-Control-Allow-Credentials"], "")
+	assert.DeepEqual(t, header["Vary"], "Origin")
+	assert.DeepEqual(t, 1, len(header))
+
+	header = generateNullOriginPreflightHeaders(config)
+	assert.DeepEqual(t, header["Access-Control-Allow-Origin"], "")
+	assert.DeepEqual(t, header["Access-Control-Max-Age"], "")
+	assert.DeepEqual(t, header["Vary"], "Origin")
+	assert.DeepEqual(t, 1, len(header))
+
+	config.AllowNullOriginCredentials = true
+	config.NullOriginMaxAge = time.Minute
+	header = generateNullOriginPreflightHeaders(config)
+	assert.DeepEqual(t, header["Access-Control-Allow-Credentials"], "true")
+	assert.DeepEqual(t, header["Access-Control-Max-Age"], "60")
+	assert.DeepEqual(t, 3, len(header))
+}
+
 func TestValidateOrigin(t *testing.T) {
 	cors := newCors(Config{
 		AllowAllOrigins: true,
@@ -225,6 +332,7 @@ func TestValidateOrigin(t *testing.T) {
 	assert.True(t, cors.validateOrigin("https://google.com"))
 	assert.True(t, cors.validateOrigin("example.com"))
 	assert.True(t, cors.validateOrigin("chrome-extension://random-extension-id"))
+	assert.True(t, cors.validateOrigin("null"))
 
 	cors = newCors(Config{
 		AllowOrigins: []string{"https://google.com", "https://github.com"},
@@ -394,9 +502,10 @@ func TestPassesAllowAllOrigins(t *testing.T) {
 
 func TestWildcard(t *testing.T) {
 	router := newTestRouter(Config{
-		AllowOrigins:  []string{"https://*.github.com", "https://api.*", "http://*", "https://facebook.com", "*.golang.org"},
-		AllowMethods:  []string{"GET"},
-		AllowWildcard: true,
+		AllowOrigins:              []string{"https://*.github.com", "https://api.*", "http://*", "https://facebook.com", "*.golang.org"},
+		AllowMethods:              []string{"GET"},
+		AllowWildcard:             true,
+		AllowPublicSuffixWildcard: true,
 	})
 
 	w := performRequest(router, "GET", "https://gist.github.com")
@@ -455,3 +564,288 @@ func TestPassesAllowOrigins2(t *testing.T) {
 	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Allow-Credentials"))
 	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Expose-Headers"))
 }
+
+func TestNullOrigin(t *testing.T) {
+	router := newTestRouter(Config{
+		AllowAllOrigins: true,
+		AllowMethods:    []string{"GET", "POST"},
+		MaxAge:          12 * time.Hour,
+	})
+
+	// null origin is allowed by AllowAllOrigins like the other origins
+	w := performRequest(router, "GET", "null")
+	assert.DeepEqual(t, "get", w.Body.String())
+	assert.DeepEqual(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+
+	router = newTestRouter(Config{
+		AllowAllOrigins: true,
+		DenyOrigins:     []string{"null"},
+	})
+
+	// null origin denied explicitly
+	w = performRequest(router, "GET", "null")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+
+	router = newTestRouter(Config{
+		AllowOrigins:     []string{"https://google.com"},
+		AllowMethods:     []string{"GET", "POST"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+		AllowOriginFunc: func(origin string) bool {
+			return true
+		},
+	})
+
+	// null origin is still passed to AllowOriginFunc, with the rules of the other origins
+	w = performRequest(router, "OPTIONS", "null")
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	assert.DeepEqual(t, "null", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.DeepEqual(t, "43200", w.Header().Get("Access-Control-Max-Age"))
+
+	router = newTestRouter(Config{
+		AllowOrigins: []string{"https://google.com"},
+		AllowOriginFunc: func(origin string) bool {
+			return origin != "null"
+		},
+	})
+
+	// null origin rejected by AllowOriginFunc
+	w = performRequest(router, "GET", "null")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+
+	router = newTestRouter(Config{
+		AllowAllOrigins:  true,
+		AllowMethods:     []string{"GET", "POST"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+		AllowNullOrigin:  true,
+	})
+
+	// allowed null origin request, without credentials
+	w = performRequest(router, "GET", "null")
+	assert.DeepEqual(t, "get", w.Body.String())
+	assert.DeepEqual(t, "null", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.DeepEqual(t, "Origin", w.Header().Get("Vary"))
+
+	// allowed null origin preflight request, not cached
+	w = performRequest(router, "OPTIONS", "null")
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	assert.DeepEqual(t, "null", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "GET,POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Max-Age"))
+
+	// other origins keep their own rules
+	w = performRequest(router, "OPTIONS", "https://google.com")
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	assert.DeepEqual(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.DeepEqual(t, "43200", w.Header().Get("Access-Control-Max-Age"))
+
+	router = newTestRouter(Config{
+		AllowMethods:               []string{"GET", "POST"},
+		AllowNullOrigin:            true,
+		AllowNullOriginCredentials: true,
+		NullOriginMaxAge:           time.Minute,
+	})
+
+	// allowed null origin preflight request, with credentials
+	w = performRequest(router, "OPTIONS", "null")
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	assert.DeepEqual(t, "null", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.DeepEqual(t, "60", w.Header().Get("Access-Control-Max-Age"))
+
+	// only the null origin is allowed
+	w = performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+}
+
+func TestDenyOrigins(t *testing.T) {
+	cors := newCors(Config{
+		AllowOrigins:       []string{"https://*.partner.com", "https://google.com"},
+		AllowWildcard:      true,
+		DenyOrigins:        []string{"https://google.com", "https://*.hacked.partner.com", "null"},
+		DenyOriginPatterns: []string{`^https://evil[0-9]+\.partner\.com$`},
+		AllowNullOrigin:    true,
+	})
+	assert.True(t, cors.validateOrigin("https://api.partner.com"))
+	assert.True(t, cors.validateOrigin("https://evil.partner.com"))
+	assert.False(t, cors.validateOrigin("https://google.com"))
+	assert.False(t, cors.validateOrigin("https://GOOGLE.com"))
+	assert.False(t, cors.validateOrigin("https://api.hacked.partner.com"))
+	assert.False(t, cors.validateOrigin("https://evil42.partner.com"))
+	assert.False(t, cors.validateOrigin("null"))
+	assert.DeepEqual(t, ErrOriginDenied, cors.checkOrigin("https://evil42.partner.com"))
+	assert.DeepEqual(t, ErrOriginNotAllowed, cors.checkOrigin("https://github.com"))
+
+	// deny rules take precedence over AllowAllOrigins and AllowOriginFunc
+	cors = newCors(Config{
+		AllowAllOrigins: true,
+		DenyOrigins:     []string{"https://*.evil.com"},
+	})
+	assert.True(t, cors.validateOrigin("https://google.com"))
+	assert.False(t, cors.validateOrigin("https://www.evil.com"))
+
+	cors = newCors(Config{
+		AllowOriginFunc: func(origin string) bool {
+			return true
+		},
+		DenyOriginPatterns: []string{`evil`},
+	})
+	assert.True(t, cors.validateOrigin("https://google.com"))
+	assert.False(t, cors.validateOrigin("https://www.evil.com"))
+	// null origin is passed to AllowOriginFunc without AllowNullOrigin
+	assert.Nil(t, cors.checkOrigin("null"))
+}
+
+func TestDenyOriginsRequest(t *testing.T) {
+	var rejected error
+	router := route.NewEngine(config.NewOptions([]config.Option{}))
+	router.Use(func(ctx context.Context, c *app.RequestContext) {
+		c.Next(ctx)
+		if last := c.Errors.Last(); last != nil {
+			rejected = last.Err
+		}
+	})
+	router.Use(New(Config{
+		AllowAllOrigins: true,
+		DenyOrigins:     []string{"https://evil.com"},
+	}))
+	router.GET("/", func(ctx context.Context, c *app.RequestContext) {
+		c.String(consts.StatusOK, "get")
+	})
+
+	w := performRequest(router, "GET", "https://evil.com")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, ErrOriginDenied, rejected)
+
+	w = performRequest(router, "OPTIONS", "https://evil.com")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Allow-Methods"))
+
+	rejected = nil
+	w = performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, "get", w.Body.String())
+	assert.DeepEqual(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	// the response depends on the origin, even with "*"
+	assert.DeepEqual(t, "Origin", w.Header().Get("Vary"))
+	assert.Nil(t, rejected)
+
+	w = performRequest(router, "OPTIONS", "https://google.com")
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	assert.DeepEqual(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "Origin", w.Header().Get("Vary"))
+
+	router = newTestRouter(Config{
+		AllowAllOrigins:    true,
+		DenyOriginPatterns: []string{`^https://Evil\.com$`},
+	})
+	w = performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, "Origin", w.Header().Get("Vary"))
+
+	// deny patterns are case-insensitive
+	w = performRequest(router, "GET", "https://evil.com")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+	w = performRequest(router, "GET", "https://EVIL.com")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+}
+
+func TestCrossOriginIsolation(t *testing.T) {
+	router := newTestRouter(Config{
+		AllowOrigins:              []string{"https://google.com"},
+		CrossOriginOpenerPolicy:   "same-origin",
+		CrossOriginEmbedderPolicy: "require-corp",
+		CrossOriginResourcePolicy: "same-site",
+	})
+
+	// no CORS request
+	w := performRequest(router, "GET", "")
+	assert.DeepEqual(t, "get", w.Body.String())
+	assert.DeepEqual(t, "same-origin", w.Header().Get("Cross-Origin-Opener-Policy"))
+	assert.DeepEqual(t, "require-corp", w.Header().Get("Cross-Origin-Embedder-Policy"))
+	assert.DeepEqual(t, "same-site", w.Header().Get("Cross-Origin-Resource-Policy"))
+
+	// allowed CORS request
+	w = performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, "get", w.Body.String())
+	assert.DeepEqual(t, "https://google.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "same-origin", w.Header().Get("Cross-Origin-Opener-Policy"))
+	assert.DeepEqual(t, "require-corp", w.Header().Get("Cross-Origin-Embedder-Policy"))
+	assert.DeepEqual(t, "same-site", w.Header().Get("Cross-Origin-Resource-Policy"))
+
+	router = newTestRouter(Config{
+		AllowOrigins: []string{"https://google.com"},
+	})
+	w = performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, "", w.Header().Get("Cross-Origin-Opener-Policy"))
+	assert.DeepEqual(t, "", w.Header().Get("Cross-Origin-Embedder-Policy"))
+	assert.DeepEqual(t, "", w.Header().Get("Cross-Origin-Resource-Policy"))
+}
+
+func TestTimingAllowOrigin(t *testing.T) {
+	router := newTestRouter(Config{
+		AllowOrigins:       []string{"https://google.com"},
+		AllowTiming:        true,
+		TimingAllowOrigins: []string{"https://rum.example.com", "https://Status.example.com"},
+		DenyOrigins:        []string{"https://status.example.com"},
+	})
+
+	// allowed CORS request
+	w := performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, "get", w.Body.String())
+	assert.DeepEqual(t, "https://google.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "https://google.com", w.Header().Get("Timing-Allow-Origin"))
+
+	// allowed CORS preflight request
+	w = performRequest(router, "OPTIONS", "https://google.com")
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	assert.DeepEqual(t, "", w.Header().Get("Timing-Allow-Origin"))
+
+	// timing only origin
+	w = performRequest(router, "GET", "https://rum.example.com")
+	assert.DeepEqual(t, "get", w.Body.String())
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "https://rum.example.com, https://status.example.com", w.Header().Get("Timing-Allow-Origin"))
+
+	// no CORS request
+	w = performRequest(router, "GET", "")
+	assert.DeepEqual(t, "get", w.Body.String())
+	assert.DeepEqual(t, "https://rum.example.com, https://status.example.com", w.Header().Get("Timing-Allow-Origin"))
+
+	// denied origin is never given timing access
+	w = performRequest(router, "GET", "https://status.example.com")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+	assert.DeepEqual(t, "", w.Header().Get("Timing-Allow-Origin"))
+
+	// other origins are rejected
+	w = performRequest(router, "GET", "https://example.com")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+	assert.DeepEqual(t, "", w.Header().Get("Timing-Allow-Origin"))
+
+	router = newTestRouter(Config{
+		AllowAllOrigins: true,
+		AllowTiming:     true,
+	})
+	w = performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "*", w.Header().Get("Timing-Allow-Origin"))
+
+	router = newTestRouter(Config{
+		AllowAllOrigins: true,
+	})
+	w = performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, "", w.Header().Get("Timing-Allow-Origin"))
+
+	assert.Panic(t, func() {
+		New(Config{
+			AllowAllOrigins:    true,
+			TimingAllowOrigins: []string{"https://*.example.com"},
+		})
+	})
+}
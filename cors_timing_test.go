@@ -28,10 +28,86 @@
 package cors
 
 import (
+	"context"
 	"testing"
 	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
 )
 
+// newBenchmarkConfig has no AllowOriginFunc, which gets a copy of the origin.
+func newBenchmarkConfig() Config {
+	return Config{
+		AllowOrigins:     []string{"http://google.com"},
+		AllowMethods:     []string{" GeT ", "get", "post", "PUT  ", "Head", "POST"},
+		AllowHeaders:     []string{"Content-type", "timeStamp "},
+		ExposeHeaders:    []string{"Data", "x-User"},
+		AllowCredentials: false,
+		MaxAge:           12 * time.Hour,
+	}
+}
+
+// handleRequest runs the middleware alone on a reused request context, so that only
+// the allocations of the middleware are counted.
+func handleRequest(cors *cors, c *app.RequestContext, method, origin string) {
+	c.ResetWithoutConn()
+	c.Request.Header.SetMethod(method)
+	c.Request.SetRequestURI("/")
+	c.Request.SetHost("facebook.com")
+	if len(origin) > 0 {
+		c.Request.Header.Set("Origin", origin)
+	}
+	cors.handle(context.Background(), c)
+}
+
+func benchmarkHandle(b *testing.B, method, origin string) {
+	cors := newCors(newBenchmarkConfig())
+	c := app.NewContext(0)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handleRequest(cors, c, method, origin)
+	}
+}
+
+func Benchmark_hertz_cors_actual(b *testing.B) {
+	benchmarkHandle(b, "GET", "http://google.com")
+}
+
+func Benchmark_hertz_cors_preflight(b *testing.B) {
+	benchmarkHandle(b, "OPTIONS", "http://google.com")
+}
+
+func Benchmark_hertz_cors_rejected(b *testing.B) {
+	benchmarkHandle(b, "GET", "http://example.com")
+}
+
+func Benchmark_hertz_cors_same_origin(b *testing.B) {
+	benchmarkHandle(b, "GET", "http://facebook.com")
+}
+
+func TestZeroAllocs(t *testing.T) {
+	cors := newCors(newBenchmarkConfig())
+	c := app.NewContext(0)
+
+	for _, r := range []struct {
+		method, origin string
+	}{
+		{"GET", "http://google.com"},
+		{"OPTIONS", "http://google.com"},
+		// rejected requests allocate the error attached to the context
+		{"GET", "http://facebook.com"},
+		{"GET", ""},
+	} {
+		allocs := testing.AllocsPerRun(100, func() {
+			handleRequest(cors, c, r.method, r.origin)
+		})
+		assert.DeepEqual(t, float64(0), allocs)
+	}
+}
+
 func Benchmark_hertz_cors(b *testing.B) {
 	router := newTestRouter(Config{
 		AllowOrigins:     []string{"http://google.com"},
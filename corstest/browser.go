@@ -0,0 +1,207 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package corstest
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+// Mode is the mode of a request of the Fetch standard.
+type Mode string
+
+const (
+	ModeCors       Mode = "cors"
+	ModeNoCors     Mode = "no-cors"
+	ModeSameOrigin Mode = "same-origin"
+	ModeNavigate   Mode = "navigate"
+)
+
+// HeaderGetter returns the value of a header, it is implemented by the response header
+// of Hertz and by http.Header.
+type HeaderGetter interface {
+	Get(key string) string
+}
+
+// BrowserRequest is a cross-origin request as made by a browser.
+type BrowserRequest struct {
+	Mode Mode
+	// Credentials is true if the credentials mode is "include"
+	Credentials bool
+	Origin      string
+	Method      string
+	// Path is the path of the requests. Default value is "/".
+	Path string
+	// Headers are the headers set by the script, like with fetch() or XMLHttpRequest
+	Headers map[string]string
+}
+
+// Response is a response seen by the browser.
+type Response struct {
+	Status int
+	Header HeaderGetter
+}
+
+// ResponseOf returns the response recorded by w.
+func ResponseOf(w *ut.ResponseRecorder) *Response {
+	return &Response{Status: w.Code, Header: w.Header()}
+}
+
+// NeedsPreflight reports whether the browser sends a preflight before the request.
+func NeedsPreflight(r *BrowserRequest) bool {
+	return r.Mode == ModeCors && (!isSafelistedMethod(r.Method) || len(unsafeHeaderNames(r.Headers)) > 0)
+}
+
+// Simulate runs the CORS-preflight fetch and the CORS check of the Fetch standard on
+// the responses, and returns nil if the browser would give the response to the script,
+// or the reason why it would not. preflight is only used if NeedsPreflight is true.
+func Simulate(r *BrowserRequest, preflight, actual *Response) error {
+	switch r.Mode {
+	case ModeNavigate:
+		return nil
+	case ModeSameOrigin:
+		return errors.New("same-origin mode does not allow cross-origin requests")
+	case ModeNoCors:
+		if !isSafelistedMethod(r.Method) {
+			return fmt.Errorf("no-cors mode does not allow method %s", r.Method)
+		}
+		// the response is opaque, the script can not read it
+		return nil
+	}
+
+	if NeedsPreflight(r) {
+		if preflight == nil {
+			return errors.New("preflight is needed but missing")
+		}
+		if err := PreflightCheck(r, preflight); err != nil {
+			return err
+		}
+	}
+	return CORSCheck(r.Origin, r.Credentials, actual.Header)
+}
+
+// CORSCheck is the CORS check of the Fetch standard, see https://fetch.spec.whatwg.org/#cors-check.
+func CORSCheck(origin string, credentials bool, h HeaderGetter) error {
+	allowOrigin := h.Get("Access-Control-Allow-Origin")
+	if len(allowOrigin) == 0 {
+		return errors.New("Access-Control-Allow-Origin is missing")
+	}
+	if !credentials && allowOrigin == "*" {
+		return nil
+	}
+	if allowOrigin != origin {
+		if allowOrigin == "*" {
+			return errors.New("Access-Control-Allow-Origin * can not be used with credentials")
+		}
+		return fmt.Errorf("Access-Control-Allow-Origin %q does not match origin %q", allowOrigin, origin)
+	}
+	if credentials && h.Get("Access-Control-Allow-Credentials") != "true" {
+		return errors.New("Access-Control-Allow-Credentials is not true for a request with credentials")
+	}
+	return nil
+}
+
+// PreflightCheck checks the response of the CORS-preflight fetch of the Fetch standard,
+// see https://fetch.spec.whatwg.org/#cors-preflight-fetch.
+func PreflightCheck(r *BrowserRequest, preflight *Response) error {
+	if preflight.Status < 200 || preflight.Status > 299 {
+		return fmt.Errorf("preflight status %d is not ok", preflight.Status)
+	}
+	if err := CORSCheck(r.Origin, r.Credentials, preflight.Header); err != nil {
+		return fmt.Errorf("preflight: %v", err)
+	}
+
+	allowMethods := preflight.Header.Get("Access-Control-Allow-Methods")
+	if !isSafelistedMethod(r.Method) && !containsToken(allowMethods, r.Method, false, !r.Credentials) {
+		return fmt.Errorf("method %s is not in Access-Control-Allow-Methods %q", r.Method, allowMethods)
+	}
+	allowHeaders := preflight.Header.Get("Access-Control-Allow-Headers")
+	for _, name := range unsafeHeaderNames(r.Headers) {
+		// the wildcard never covers Authorization
+		wildcard := !r.Credentials && !strings.EqualFold(name, "Authorization")
+		if !containsToken(allowHeaders, name, true, wildcard) {
+			return fmt.Errorf("header %s is not in Access-Control-Allow-Headers %q", name, allowHeaders)
+		}
+	}
+	return nil
+}
+
+// Requests returns the requests sent by the browser, preflight is nil if it is not needed.
+func (r *BrowserRequest) Requests() (preflight, actual *Request) {
+	if NeedsPreflight(r) {
+		preflight = Preflight(r.Origin, r.Method, unsafeHeaderNames(r.Headers)...).WithPath(r.Path)
+	}
+	actual = Actual(r.Method, r.Origin).WithPath(r.Path)
+	for key, value := range r.Headers {
+		actual.WithHeader(key, value)
+	}
+	return preflight, actual
+}
+
+// Run sends the requests of the browser to the engine, the preflight first if needed,
+// and simulates the checks on the responses.
+func Run(engine *route.Engine, r *BrowserRequest) error {
+	preflightRequest, actualRequest := r.Requests()
+	var preflight *Response
+	if preflightRequest != nil {
+		preflight = ResponseOf(preflightRequest.Do(engine))
+	}
+	return Simulate(r, preflight, ResponseOf(actualRequest.Do(engine)))
+}
+
+// unsafeHeaderNames returns the names of the headers that are not CORS-safelisted, see
+// https://fetch.spec.whatwg.org/#cors-unsafe-request-header-names.
+func unsafeHeaderNames(headers map[string]string) []string {
+	var names []string
+	for key, value := range headers {
+		if !isSafelistedHeader(key, value) {
+			names = append(names, strings.ToLower(key))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isSafelistedHeader(key, value string) bool {
+	if len(value) > 128 {
+		return false
+	}
+	switch strings.ToLower(key) {
+	case "accept", "accept-language", "content-language":
+		return true
+	case "content-type":
+		mime := strings.ToLower(strings.TrimSpace(strings.SplitN(value, ";", 2)[0]))
+		return mime == "application/x-www-form-urlencoded" || mime == "multipart/form-data" || mime == "text/plain"
+	}
+	return false
+}
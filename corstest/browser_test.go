@@ -0,0 +1,144 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package corstest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/hertz-contrib/cors"
+)
+
+func TestNeedsPreflight(t *testing.T) {
+	assert.False(t, NeedsPreflight(&BrowserRequest{Mode: ModeCors, Method: "GET"}))
+	assert.False(t, NeedsPreflight(&BrowserRequest{Mode: ModeCors, Method: "POST", Headers: map[string]string{
+		"Content-Type": "text/plain; charset=utf-8",
+		"Accept":       "application/json",
+	}}))
+	assert.True(t, NeedsPreflight(&BrowserRequest{Mode: ModeCors, Method: "PUT"}))
+	assert.True(t, NeedsPreflight(&BrowserRequest{Mode: ModeCors, Method: "POST", Headers: map[string]string{
+		"Content-Type": "application/json",
+	}}))
+	assert.True(t, NeedsPreflight(&BrowserRequest{Mode: ModeCors, Method: "GET", Headers: map[string]string{
+		"X-Token": "1",
+	}}))
+	assert.False(t, NeedsPreflight(&BrowserRequest{Mode: ModeNoCors, Method: "GET", Headers: map[string]string{
+		"X-Token": "1",
+	}}))
+}
+
+func TestCORSCheck(t *testing.T) {
+	header := func(kv ...string) http.Header {
+		h := http.Header{}
+		for i := 0; i < len(kv); i += 2 {
+			h.Set(kv[i], kv[i+1])
+		}
+		return h
+	}
+	origin := "https://google.com"
+
+	assert.Nil(t, CORSCheck(origin, false, header("Access-Control-Allow-Origin", "*")))
+	assert.Nil(t, CORSCheck(origin, false, header("Access-Control-Allow-Origin", origin)))
+	assert.Nil(t, CORSCheck(origin, true, header(
+		"Access-Control-Allow-Origin", origin,
+		"Access-Control-Allow-Credentials", "true",
+	)))
+	assert.NotNil(t, CORSCheck(origin, false, header()))
+	assert.NotNil(t, CORSCheck(origin, false, header("Access-Control-Allow-Origin", "https://example.com")))
+	assert.NotNil(t, CORSCheck(origin, true, header("Access-Control-Allow-Origin", "*")))
+	assert.NotNil(t, CORSCheck(origin, true, header("Access-Control-Allow-Origin", origin)))
+}
+
+func TestSimulate(t *testing.T) {
+	router := newTestRouter(cors.Config{
+		AllowOrigins:     []string{"https://google.com"},
+		AllowMethods:     []string{"GET", "PUT"},
+		AllowHeaders:     []string{"Content-Type", "X-Token"},
+		AllowCredentials: true,
+	})
+
+	for _, c := range []struct {
+		request *BrowserRequest
+		pass    bool
+	}{
+		{&BrowserRequest{Mode: ModeCors, Origin: "https://google.com", Method: "GET"}, true},
+		{&BrowserRequest{Mode: ModeCors, Origin: "https://google.com", Method: "GET", Credentials: true}, true},
+		{&BrowserRequest{Mode: ModeCors, Origin: "https://google.com", Method: "PUT", Headers: map[string]string{
+			"Content-Type": "application/json",
+			"X-Token":      "1",
+		}}, true},
+		{&BrowserRequest{Mode: ModeCors, Origin: "https://google.com", Method: "DELETE"}, false},
+		{&BrowserRequest{Mode: ModeCors, Origin: "https://google.com", Method: "GET", Headers: map[string]string{
+			"X-Other": "1",
+		}}, false},
+		{&BrowserRequest{Mode: ModeCors, Origin: "https://example.com", Method: "GET"}, false},
+		{&BrowserRequest{Mode: ModeNoCors, Origin: "https://example.com", Method: "GET"}, true},
+		{&BrowserRequest{Mode: ModeNoCors, Origin: "https://google.com", Method: "PUT"}, false},
+		{&BrowserRequest{Mode: ModeSameOrigin, Origin: "https://google.com", Method: "GET"}, false},
+		{&BrowserRequest{Mode: ModeNavigate, Origin: "https://example.com", Method: "GET"}, true},
+	} {
+		err := Run(router, c.request)
+		assert.DeepEqual(t, c.pass, err == nil)
+	}
+}
+
+func TestRequestsPath(t *testing.T) {
+	r := &BrowserRequest{Mode: ModeCors, Origin: "https://google.com", Method: "PUT", Path: "/items"}
+	preflight, actual := r.Requests()
+	assert.DeepEqual(t, "/items", preflight.Path)
+	assert.DeepEqual(t, "/items", actual.Path)
+
+	router := newTestRouter(cors.Config{
+		AllowOrigins: []string{"https://google.com"},
+		AllowMethods: []string{"PUT"},
+	})
+	router.PUT("/items", func(ctx context.Context, c *app.RequestContext) {
+		c.Header("X-Path", string(c.Path()))
+		c.String(consts.StatusOK, "items")
+	})
+	w := actual.Do(router)
+	assert.DeepEqual(t, "/items", w.Header().Get("X-Path"))
+	assert.Nil(t, Run(router, r))
+}
+
+func TestSimulateMissingPreflight(t *testing.T) {
+	r := &BrowserRequest{Mode: ModeCors, Origin: "https://google.com", Method: "PUT"}
+	err := Simulate(r, nil, &Response{Status: 200, Header: http.Header{}})
+	assert.DeepEqual(t, "preflight is needed but missing", err.Error())
+
+	preflight := &Response{Status: 204, Header: http.Header{
+		"Access-Control-Allow-Origin":  []string{"https://google.com"},
+		"Access-Control-Allow-Methods": []string{"GET,POST"},
+	}}
+	err = Simulate(r, preflight, &Response{Status: 200, Header: http.Header{}})
+	assert.DeepEqual(t, `method PUT is not in Access-Control-Allow-Methods "GET,POST"`, err.Error())
+}
@@ -0,0 +1,213 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+// Package corstest provides helpers to test the CORS policy of a route.Engine. It builds
+// preflight and actual requests with the right headers, runs them against the engine
+// and checks the responses with readable failure messages.
+//
+//	w := corstest.Preflight("https://foo.com", "PUT", "Content-Type").Do(router)
+//	corstest.AssertPreflightAllows(t, w, "PUT", "Content-Type")
+package corstest
+
+import (
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+// TestingT is the subset of testing.TB used by the assertions.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Request is a cross-origin request to run against a route.Engine.
+type Request struct {
+	// Method is the HTTP method of the request.
+	Method string
+	// Path is the path of the request. Default value is "/".
+	Path string
+	// Host is the Host of the request, used by the same-origin check.
+	Host string
+	// Origin is the Origin header of the request, no Origin is sent if it is empty.
+	Origin string
+	// Headers are the other headers of the request.
+	Headers []ut.Header
+}
+
+// Actual returns an actual cross-origin request with the method from the origin.
+func Actual(method, origin string) *Request {
+	return &Request{Method: method, Origin: origin}
+}
+
+// Preflight returns the preflight request sent by a browser before a request with the
+// method and the non safelisted headers from the origin.
+func Preflight(origin, method string, headers ...string) *Request {
+	r := &Request{Method: consts.MethodOptions, Origin: origin}
+	r.Headers = append(r.Headers, ut.Header{Key: "Access-Control-Request-Method", Value: method})
+	if len(headers) > 0 {
+		r.Headers = append(r.Headers, ut.Header{Key: "Access-Control-Request-Headers", Value: strings.ToLower(strings.Join(headers, ","))})
+	}
+	return r
+}
+
+// WithPath sets the path of the request.
+func (r *Request) WithPath(path string) *Request {
+	r.Path = path
+	return r
+}
+
+// WithHost sets the Host of the request.
+func (r *Request) WithHost(host string) *Request {
+	r.Host = host
+	return r
+}
+
+// WithHeader adds a header to the request.
+func (r *Request) WithHeader(key, value string) *Request {
+	r.Headers = append(r.Headers, ut.Header{Key: key, Value: value})
+	return r
+}
+
+// Do runs the request against the engine and returns the response.
+func (r *Request) Do(engine *route.Engine) *ut.ResponseRecorder {
+	url := r.Path
+	if len(url) == 0 {
+		url = "/"
+	}
+	headers := append([]ut.Header(nil), r.Headers...)
+	if len(r.Host) > 0 {
+		url = "http://" + r.Host + url
+		headers = append(headers, ut.Header{Key: "Host", Value: r.Host})
+	}
+	if len(r.Origin) > 0 {
+		headers = append(headers, ut.Header{Key: "Origin", Value: r.Origin})
+	}
+	return ut.PerformRequest(engine, r.Method, url, nil, headers...)
+}
+
+// AssertAllowed checks that the response allows the origin to read it.
+func AssertAllowed(t TestingT, w *ut.ResponseRecorder, origin string) bool {
+	t.Helper()
+	allowOrigin := w.Header().Get("Access-Control-Allow-Origin")
+	if w.Code == consts.StatusForbidden {
+		t.Errorf("cors: expected origin %q to be allowed, but got status 403%s", origin, describe(w))
+		return false
+	}
+	if allowOrigin != origin && allowOrigin != "*" {
+		t.Errorf("cors: expected origin %q to be allowed, but Access-Control-Allow-Origin is %q%s", origin, allowOrigin, describe(w))
+		return false
+	}
+	if allowOrigin == "*" && w.Header().Get("Access-Control-Allow-Credentials") == "true" {
+		t.Errorf("cors: expected origin %q to be allowed, but Access-Control-Allow-Origin \"*\" can not be used with credentials%s", origin, describe(w))
+		return false
+	}
+	return true
+}
+
+// AssertRejected checks that the response does not allow any origin to read it.
+func AssertRejected(t TestingT, w *ut.ResponseRecorder) bool {
+	t.Helper()
+	if allowOrigin := w.Header().Get("Access-Control-Allow-Origin"); len(allowOrigin) > 0 {
+		t.Errorf("cors: expected request to be rejected, but Access-Control-Allow-Origin is %q%s", allowOrigin, describe(w))
+		return false
+	}
+	return true
+}
+
+// AssertPreflightAllows checks that the preflight response allows a request with the
+// method and the headers.
+func AssertPreflightAllows(t TestingT, w *ut.ResponseRecorder, method string, headers ...string) bool {
+	t.Helper()
+	if w.Code < 200 || w.Code > 299 {
+		t.Errorf("cors: expected preflight to allow %s, but got status %d%s", method, w.Code, describe(w))
+		return false
+	}
+	if len(w.Header().Get("Access-Control-Allow-Origin")) == 0 {
+		t.Errorf("cors: expected preflight to allow %s, but Access-Control-Allow-Origin is missing%s", method, describe(w))
+		return false
+	}
+	wildcard := w.Header().Get("Access-Control-Allow-Credentials") != "true"
+	allowMethods := w.Header().Get("Access-Control-Allow-Methods")
+	if !isSafelistedMethod(method) && !containsToken(allowMethods, method, false, wildcard) {
+		t.Errorf("cors: expected preflight to allow method %s, but Access-Control-Allow-Methods is %q%s", method, allowMethods, describe(w))
+		return false
+	}
+	allowHeaders := w.Header().Get("Access-Control-Allow-Headers")
+	var missing []string
+	for _, h := range headers {
+		if !containsToken(allowHeaders, h, true, wildcard && !strings.EqualFold(h, "Authorization")) {
+			missing = append(missing, h)
+		}
+	}
+	if len(missing) > 0 {
+		t.Errorf("cors: expected preflight to allow headers %s, but Access-Control-Allow-Headers is %q%s", strings.Join(missing, ","), allowHeaders, describe(w))
+		return false
+	}
+	return true
+}
+
+func isSafelistedMethod(method string) bool {
+	return method == consts.MethodGet || method == consts.MethodHead || method == consts.MethodPost
+}
+
+func containsToken(list, token string, fold, wildcard bool) bool {
+	for _, v := range strings.Split(list, ",") {
+		v = strings.TrimSpace(v)
+		if (wildcard && v == "*") || v == token || (fold && strings.EqualFold(v, token)) {
+			return true
+		}
+	}
+	return false
+}
+
+// describe lists the CORS headers of the response for failure messages.
+func describe(w *ut.ResponseRecorder) string {
+	var b strings.Builder
+	for _, key := range []string{
+		"Access-Control-Allow-Origin",
+		"Access-Control-Allow-Credentials",
+		"Access-Control-Allow-Methods",
+		"Access-Control-Allow-Headers",
+		"Access-Control-Expose-Headers",
+		"Access-Control-Max-Age",
+		"Vary",
+	} {
+		if v := w.Header().Get(key); len(v) > 0 {
+			b.WriteString("\n\t")
+			b.WriteString(key)
+			b.WriteString(": ")
+			b.WriteString(v)
+		}
+	}
+	if b.Len() == 0 {
+		return "\n\t(no CORS headers)"
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package corstest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/hertz-contrib/cors"
+)
+
+// recorder records the failures of the assertions.
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func newTestRouter(c cors.Config) *route.Engine {
+	router := route.NewEngine(config.NewOptions([]config.Option{}))
+	router.Use(cors.New(c))
+	router.Any("/", func(ctx context.Context, c *app.RequestContext) {
+		c.String(consts.StatusOK, "ok")
+	})
+	return router
+}
+
+func TestAssertions(t *testing.T) {
+	router := newTestRouter(cors.Config{
+		AllowOrigins: []string{"https://google.com"},
+		AllowMethods: []string{"GET", "PUT"},
+		AllowHeaders: []string{"Content-Type", "X-Token"},
+		MaxAge:       time.Hour,
+	})
+
+	w := Actual("GET", "https://google.com").Do(router)
+	assert.True(t, AssertAllowed(t, w, "https://google.com"))
+
+	w = Actual("GET", "https://example.com").Do(router)
+	assert.True(t, AssertRejected(t, w))
+
+	w = Actual("GET", "https://google.com").WithHost("google.com").WithPath("/").Do(router)
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+
+	w = Preflight("https://google.com", "PUT", "Content-Type", "x-token").Do(router)
+	assert.True(t, AssertPreflightAllows(t, w, "PUT", "Content-Type", "x-token"))
+	assert.True(t, AssertPreflightAllows(t, w, "POST"))
+}
+
+func TestAssertionsFailure(t *testing.T) {
+	router := newTestRouter(cors.Config{
+		AllowOrigins: []string{"https://google.com"},
+		AllowMethods: []string{"GET"},
+		AllowHeaders: []string{"Content-Type"},
+	})
+
+	r := &recorder{}
+	w := Actual("GET", "https://example.com").Do(router)
+	assert.False(t, AssertAllowed(r, w, "https://example.com"))
+	assert.DeepEqual(t, []string{"cors: expected origin \"https://example.com\" to be allowed, but got status 403\n\t(no CORS headers)"}, r.errors)
+
+	r = &recorder{}
+	w = Actual("GET", "https://google.com").Do(router)
+	assert.False(t, AssertRejected(r, w))
+	assert.DeepEqual(t, []string{"cors: expected request to be rejected, but Access-Control-Allow-Origin is \"https://google.com\"\n\tAccess-Control-Allow-Origin: https://google.com\n\tVary: Origin"}, r.errors)
+
+	r = &recorder{}
+	w = Preflight("https://google.com", "DELETE").Do(router)
+	assert.False(t, AssertPreflightAllows(r, w, "DELETE"))
+	assert.DeepEqual(t, 1, len(r.errors))
+	assert.DeepEqual(t, "cors: expected preflight to allow method DELETE, but Access-Control-Allow-Methods is \"GET\"\n\tAccess-Control-Allow-Origin: https://google.com\n\tAccess-Control-Allow-Methods: GET\n\tAccess-Control-Allow-Headers: Content-Type\n\tVary: Origin", r.errors[0])
+
+	r = &recorder{}
+	w = Preflight("https://google.com", "GET", "X-Token", "Content-Type").Do(router)
+	assert.False(t, AssertPreflightAllows(r, w, "GET", "X-Token", "Content-Type"))
+	assert.DeepEqual(t, 1, len(r.errors))
+	assert.DeepEqual(t, "cors: expected preflight to allow headers X-Token, but Access-Control-Allow-Headers is \"Content-Type\"\n\tAccess-Control-Allow-Origin: https://google.com\n\tAccess-Control-Allow-Methods: GET\n\tAccess-Control-Allow-Headers: Content-Type\n\tVary: Origin", r.errors[0])
+
+	r = &recorder{}
+	w = Preflight("https://example.com", "GET").Do(router)
+	assert.False(t, AssertPreflightAllows(r, w, "GET"))
+	assert.DeepEqual(t, "cors: expected preflight to allow GET, but got status 403\n\t(no CORS headers)", r.errors[0])
+}
@@ -0,0 +1,131 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// SafelistedResponseHeaders are the CORS-safelisted response headers, which are always
+// exposed by the browser, see https://fetch.spec.whatwg.org/#cors-safelisted-response-header-name.
+var SafelistedResponseHeaders = []string{
+	"Cache-Control",
+	"Content-Language",
+	"Content-Length",
+	"Content-Type",
+	"Expires",
+	"Last-Modified",
+	"Pragma",
+}
+
+// middlewareHeaders are the lower case response headers set by the middleware itself,
+// which are never exposed dynamically.
+var middlewareHeaders = []string{
+	"vary",
+	"timing-allow-origin",
+	"cross-origin-opener-policy",
+	"cross-origin-embedder-policy",
+	"cross-origin-resource-policy",
+	"retry-after",
+}
+
+// handleDynamicExpose adds the response headers set by the handler to
+// Access-Control-Expose-Headers.
+func (cors *cors) handleDynamicExpose(c *app.RequestContext) {
+	var exposed []string
+	c.Response.Header.VisitAll(func(key, value []byte) {
+		if k := strings.ToLower(string(key)); cors.isDynamicExposable(k) {
+			exposed = append(exposed, k)
+		}
+	})
+	mergeHeaderList(c, "Access-Control-Expose-Headers", exposed, normalizeHeaderKey)
+}
+
+// isDynamicExposable reports whether the lower case response header key can be exposed.
+func (cors *cors) isDynamicExposable(key string) bool {
+	if strings.HasPrefix(key, "access-control-") || key == "set-cookie" || key == "set-cookie2" {
+		return false
+	}
+	for _, h := range SafelistedResponseHeaders {
+		if strings.EqualFold(h, key) {
+			return false
+		}
+	}
+	if contains(middlewareHeaders, key) {
+		return false
+	}
+	if matchHeaderRules(cors.dynamicExposeDeny, key) {
+		return false
+	}
+	return len(cors.dynamicExposeAllow) == 0 || matchHeaderRules(cors.dynamicExposeAllow, key)
+}
+
+func matchHeaderRules(rules []string, key string) bool {
+	for _, rule := range rules {
+		if strings.HasSuffix(rule, "*") {
+			if strings.HasPrefix(key, rule[:len(rule)-1]) {
+				return true
+			}
+			continue
+		}
+		if rule == key {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeHeaderList adds the values to the comma separated list of the response header
+// key, skipping the values already present.
+func mergeHeaderList(c *app.RequestContext, key string, values []string, conv converter) {
+	if len(values) == 0 {
+		return
+	}
+	c.Response.Header.Set(key, mergeList(string(c.Response.Header.Peek(key)), values, conv))
+}
+
+// mergeList adds the values to the comma separated list, skipping the values already present.
+func mergeList(list string, values []string, conv converter) string {
+	var merged []string
+	seen := make(map[string]bool)
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); len(v) > 0 && !seen[strings.ToLower(v)] {
+			seen[strings.ToLower(v)] = true
+			merged = append(merged, v)
+		}
+	}
+	for _, v := range normalize(values) {
+		if len(v) > 0 && !seen[v] {
+			seen[v] = true
+			merged = append(merged, conv(v))
+		}
+	}
+	return strings.Join(merged, ",")
+}
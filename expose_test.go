@@ -0,0 +1,112 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func TestDynamicExposeHeaders(t *testing.T) {
+	router := newTestRouter(Config{
+		AllowOrigins:         []string{"https://google.com"},
+		ExposeHeaders:        []string{"X-Static"},
+		DynamicExposeHeaders: true,
+		DynamicExposeAllow:   []string{"Link", "X-RateLimit-*", "X-Internal-*", "Content-Type"},
+		DynamicExposeDeny:    []string{"X-Internal-Secret"},
+	})
+	router.GET("/items", func(ctx context.Context, c *app.RequestContext) {
+		c.Header("Link", `</items?page=2>; rel="next"`)
+		c.Header("X-RateLimit-Limit", "100")
+		c.Header("X-RateLimit-Remaining", "99")
+		c.Header("X-Internal-Trace", "abc")
+		c.Header("X-Internal-Secret", "secret")
+		c.Header("X-Other", "other")
+		c.SetCookie("session", "1", 0, "/", "", 0, false, false)
+		c.String(consts.StatusOK, "items")
+	})
+
+	// allowed CORS request
+	w := performRequestPath(router, "GET", "/items", "https://google.com")
+	assert.DeepEqual(t, "items", w.Body.String())
+	assert.DeepEqual(t, "X-Static,Link,X-Ratelimit-Limit,X-Ratelimit-Remaining,X-Internal-Trace", w.Header().Get("Access-Control-Expose-Headers"))
+
+	// allowed CORS preflight request
+	w = performRequestPath(router, "OPTIONS", "/items", "https://google.com")
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Expose-Headers"))
+
+	// no CORS request
+	w = performRequestPath(router, "GET", "/items", "")
+	assert.DeepEqual(t, "items", w.Body.String())
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Expose-Headers"))
+
+	router = newTestRouter(Config{
+		AllowAllOrigins:      true,
+		DynamicExposeHeaders: true,
+	})
+	router.GET("/items", func(ctx context.Context, c *app.RequestContext) {
+		c.Header("X-Other", "other")
+		c.Header("Cache-Control", "no-cache")
+		c.String(consts.StatusOK, "items")
+	})
+
+	// all headers except the safelisted ones
+	w = performRequestPath(router, "GET", "/items", "https://google.com")
+	assert.DeepEqual(t, "items", w.Body.String())
+	assert.DeepEqual(t, "X-Other", w.Header().Get("Access-Control-Expose-Headers"))
+
+	router = newTestRouter(Config{
+		AllowOrigins:              []string{"https://google.com"},
+		AllowTiming:               true,
+		CrossOriginResourcePolicy: "same-site",
+		DynamicExposeHeaders:      true,
+	})
+	router.GET("/items", func(ctx context.Context, c *app.RequestContext) {
+		c.Header("X-Other", "other")
+		c.String(consts.StatusOK, "items")
+	})
+
+	// headers set by the middleware are not exposed
+	w = performRequestPath(router, "GET", "/items", "https://google.com")
+	assert.DeepEqual(t, "items", w.Body.String())
+	assert.DeepEqual(t, "Origin", w.Header().Get("Vary"))
+	assert.DeepEqual(t, "https://google.com", w.Header().Get("Timing-Allow-Origin"))
+	assert.DeepEqual(t, "X-Other", w.Header().Get("Access-Control-Expose-Headers"))
+
+	assert.Panic(t, func() {
+		New(Config{
+			AllowAllOrigins:    true,
+			DynamicExposeAllow: []string{"Link"},
+		})
+	})
+}
@@ -0,0 +1,67 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"errors"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// ErrCrossSiteRequest is attached to the request context when a cross-site request is
+// rejected by the resource isolation policy.
+var ErrCrossSiteRequest = errors.New("cors: cross-site request rejected by resource isolation policy")
+
+// allowResourceIsolation implements a resource isolation policy based on the Fetch
+// Metadata request headers, see https://web.dev/fetch-metadata/.
+//
+// Requests are allowed when the browser does not send Sec-Fetch-Site, when they come
+// from the same origin, the same site or the user, when they are top-level navigations,
+// when the path is allowlisted, or when the origin passes the origin rules of Config.
+func (cors *cors) allowResourceIsolation(c *app.RequestContext) bool {
+	site := c.Request.Header.Peek("Sec-Fetch-Site")
+	switch string(site) {
+	case "", "same-origin", "same-site", "none":
+		return true
+	}
+
+	if string(c.Request.Header.Peek("Sec-Fetch-Mode")) == "navigate" && string(c.Request.Method()) == "GET" {
+		switch string(c.Request.Header.Peek("Sec-Fetch-Dest")) {
+		case "object", "embed":
+		default:
+			return true
+		}
+	}
+
+	if _, ok := cors.isolationAllowPaths[string(c.Request.URI().Path())]; ok {
+		return true
+	}
+
+	origin := c.Request.Header.Peek("Origin")
+	return len(origin) > 0 && cors.checkOrigin(bytes2str(origin)) == nil
+}
@@ -0,0 +1,119 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func fetchMetadata(site, mode, dest string) []ut.Header {
+	return []ut.Header{
+		{Key: "Sec-Fetch-Site", Value: site},
+		{Key: "Sec-Fetch-Mode", Value: mode},
+		{Key: "Sec-Fetch-Dest", Value: dest},
+	}
+}
+
+func TestResourceIsolation(t *testing.T) {
+	router := newTestRouter(Config{
+		AllowOrigins:                []string{"https://google.com"},
+		AllowMethods:                []string{"GET", "POST"},
+		DenyOrigins:                 []string{"https://evil.google.com"},
+		ResourceIsolation:           true,
+		ResourceIsolationAllowPaths: []string{"/webhook"},
+	})
+	router.POST("/webhook", func(ctx context.Context, c *app.RequestContext) {
+		c.String(consts.StatusOK, "webhook")
+	})
+
+	// browsers without fetch metadata
+	w := performRequest(router, "POST", "")
+	assert.DeepEqual(t, "post", w.Body.String())
+
+	// same-origin, same-site and user initiated requests
+	for _, site := range []string{"same-origin", "same-site", "none"} {
+		w = performRequest(router, "POST", "", fetchMetadata(site, "no-cors", "empty")...)
+		assert.DeepEqual(t, "post", w.Body.String())
+	}
+
+	// cross-site navigation
+	w = performRequest(router, "GET", "", fetchMetadata("cross-site", "navigate", "document")...)
+	assert.DeepEqual(t, "get", w.Body.String())
+
+	// cross-site navigation into an object or embed
+	w = performRequest(router, "GET", "", fetchMetadata("cross-site", "navigate", "object")...)
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+
+	// cross-site navigation by POST
+	w = performRequest(router, "POST", "https://example.com", fetchMetadata("cross-site", "navigate", "document")...)
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+
+	// cross-site no-cors POST never triggers CORS
+	w = performRequest(router, "POST", "https://example.com", fetchMetadata("cross-site", "no-cors", "empty")...)
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+	assert.DeepEqual(t, "", w.Body.String())
+
+	// cross-site subresource without origin
+	w = performRequest(router, "GET", "", fetchMetadata("cross-site", "no-cors", "image")...)
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+
+	// cross-site request from an allowed origin
+	w = performRequest(router, "POST", "https://google.com", fetchMetadata("cross-site", "cors", "empty")...)
+	assert.DeepEqual(t, "post", w.Body.String())
+	assert.DeepEqual(t, "https://google.com", w.Header().Get("Access-Control-Allow-Origin"))
+
+	// cross-site request from a denied origin
+	w = performRequest(router, "POST", "https://evil.google.com", fetchMetadata("cross-site", "cors", "empty")...)
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+
+	// allowlisted path
+	w = ut.PerformRequest(router, "POST", "/webhook", nil, fetchMetadata("cross-site", "no-cors", "empty")...)
+	assert.DeepEqual(t, "webhook", w.Body.String())
+}
+
+func TestResourceIsolationDisabled(t *testing.T) {
+	router := newTestRouter(Config{
+		AllowOrigins: []string{"https://google.com"},
+	})
+
+	w := performRequest(router, "POST", "", fetchMetadata("cross-site", "no-cors", "empty")...)
+	assert.DeepEqual(t, "post", w.Body.String())
+
+	assert.Panic(t, func() {
+		New(Config{
+			AllowAllOrigins:             true,
+			ResourceIsolationAllowPaths: []string{"/webhook"},
+		})
+	})
+}
@@ -0,0 +1,104 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// requestHeadersKey is the key of the per request CORS headers in the request context.
+const requestHeadersKey = "github.com/hertz-contrib/cors.requestHeaders"
+
+// requestHeaders holds the CORS headers added by a handler for the current request.
+type requestHeaders struct {
+	exposeHeaders []string
+	allowHeaders  []string
+	maxAge        *time.Duration
+}
+
+func getRequestHeaders(c *app.RequestContext, create bool) *requestHeaders {
+	if v, ok := c.Get(requestHeadersKey); ok {
+		return v.(*requestHeaders)
+	}
+	if !create {
+		return nil
+	}
+	h := &requestHeaders{}
+	c.Set(requestHeadersKey, h)
+	return h
+}
+
+// ExposeHeaders adds headers to Access-Control-Expose-Headers of the current response.
+// It is merged by the middleware after the handler runs.
+func ExposeHeaders(c *app.RequestContext, headers ...string) {
+	h := getRequestHeaders(c, true)
+	h.exposeHeaders = append(h.exposeHeaders, headers...)
+}
+
+// AllowHeaders adds headers to Access-Control-Allow-Headers of the current preflight
+// response. It takes effect in a handler registered for OPTIONS on the route, which only
+// runs on preflight requests if Config.PreflightHandlers is set.
+func AllowHeaders(c *app.RequestContext, headers ...string) {
+	h := getRequestHeaders(c, true)
+	h.allowHeaders = append(h.allowHeaders, headers...)
+}
+
+// SetMaxAge overrides Access-Control-Max-Age of the current preflight response. It takes
+// effect in a handler registered for OPTIONS on the route, like AllowHeaders. A zero
+// maxAge disables caching.
+func SetMaxAge(c *app.RequestContext, maxAge time.Duration) {
+	h := getRequestHeaders(c, true)
+	h.maxAge = &maxAge
+}
+
+// handleAfter extends the headers of an allowed actual cross-origin request after the
+// handler runs.
+func (cors *cors) handleAfter(c *app.RequestContext) {
+	if cors.dynamicExpose {
+		cors.handleDynamicExpose(c)
+	}
+	if h := getRequestHeaders(c, false); h != nil {
+		mergeHeaderList(c, "Access-Control-Expose-Headers", h.exposeHeaders, normalizeHeaderKey)
+	}
+}
+
+// handlePreflightAfter extends the headers of an allowed preflight request after the
+// OPTIONS handler runs.
+func (cors *cors) handlePreflightAfter(c *app.RequestContext) {
+	h := getRequestHeaders(c, false)
+	if h == nil {
+		return
+	}
+	mergeHeaderList(c, "Access-Control-Allow-Headers", h.allowHeaders, normalizeHeaderKey)
+	if h.maxAge != nil {
+		c.Response.Header.Set("Access-Control-Max-Age", strconv.FormatInt(int64(*h.maxAge/time.Second), 10))
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func TestHandlerHelpers(t *testing.T) {
+	router := newTestRouter(Config{
+		AllowOrigins:      []string{"https://google.com"},
+		AllowHeaders:      []string{"Content-Type"},
+		ExposeHeaders:     []string{"X-Static"},
+		MaxAge:            12 * time.Hour,
+		PreflightHandlers: true,
+	})
+	router.GET("/items", func(ctx context.Context, c *app.RequestContext) {
+		ExposeHeaders(c, "Link", "x-static")
+		ExposeHeaders(c, "X-Total-Count")
+		c.String(consts.StatusOK, "items")
+	})
+	router.OPTIONS("/items", func(ctx context.Context, c *app.RequestContext) {
+		AllowHeaders(c, "X-Page-Token")
+		SetMaxAge(c, time.Minute)
+		c.String(consts.StatusOK, "options")
+	})
+
+	// allowed CORS request
+	w := performRequestPath(router, "GET", "/items", "https://google.com")
+	assert.DeepEqual(t, "items", w.Body.String())
+	assert.DeepEqual(t, "X-Static,Link,X-Total-Count", w.Header().Get("Access-Control-Expose-Headers"))
+
+	// allowed CORS preflight request with an OPTIONS handler
+	w = performRequestPath(router, "OPTIONS", "/items", "https://google.com")
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	assert.DeepEqual(t, "", w.Body.String())
+	assert.DeepEqual(t, "Content-Type,X-Page-Token", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.DeepEqual(t, "60", w.Header().Get("Access-Control-Max-Age"))
+
+	// allowed CORS preflight request without an OPTIONS handler
+	w = performRequestPath(router, "OPTIONS", "/", "https://google.com")
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	assert.DeepEqual(t, "Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.DeepEqual(t, "43200", w.Header().Get("Access-Control-Max-Age"))
+
+	// no CORS request
+	w = performRequestPath(router, "GET", "/items", "")
+	assert.DeepEqual(t, "items", w.Body.String())
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Expose-Headers"))
+
+	// deny CORS request
+	w = performRequestPath(router, "GET", "/items", "https://example.com")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Expose-Headers"))
+}
+
+func TestPreflightHandlersDisabled(t *testing.T) {
+	router := newTestRouter(Config{
+		AllowOrigins: []string{"https://google.com"},
+		AllowHeaders: []string{"Content-Type"},
+		MaxAge:       12 * time.Hour,
+	})
+	called := false
+	router.OPTIONS("/items", func(ctx context.Context, c *app.RequestContext) {
+		called = true
+		AllowHeaders(c, "X-Page-Token")
+		c.String(consts.StatusOK, "options")
+	})
+
+	// allowed CORS preflight request, answered by the middleware alone
+	w := performRequestPath(router, "OPTIONS", "/items", "https://google.com")
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	assert.DeepEqual(t, "Content-Type", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.DeepEqual(t, "43200", w.Header().Get("Access-Control-Max-Age"))
+	assert.False(t, called)
+
+	// OPTIONS request without Origin still reaches the handler
+	w = performRequestPath(router, "OPTIONS", "/items", "")
+	assert.DeepEqual(t, "options", w.Body.String())
+	assert.True(t, called)
+}
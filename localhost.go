@@ -0,0 +1,84 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"net"
+	"strings"
+)
+
+// isLocalhostOrigin reports whether the origin is http or https on localhost, an IPv4
+// address of 127.0.0.0/8 or [::1], with any port or none.
+func isLocalhostOrigin(origin string) bool {
+	var host string
+	switch {
+	case strings.HasPrefix(origin, "http://"):
+		host = origin[len("http://"):]
+	case strings.HasPrefix(origin, "https://"):
+		host = origin[len("https://"):]
+	default:
+		return false
+	}
+
+	port := ""
+	if strings.HasPrefix(host, "[") {
+		end := strings.IndexByte(host, ']')
+		if end < 0 {
+			return false
+		}
+		host, port = host[1:end], host[end+1:]
+		if ip := net.ParseIP(host); ip == nil || ip.To4() != nil || !ip.IsLoopback() {
+			return false
+		}
+	} else {
+		if i := strings.IndexByte(host, ':'); i >= 0 {
+			host, port = host[:i], host[i:]
+		}
+		if !strings.EqualFold(host, "localhost") {
+			if ip := net.ParseIP(host); ip == nil || ip.To4() == nil || !ip.IsLoopback() {
+				return false
+			}
+		}
+	}
+	return len(port) == 0 || port[0] == ':' && isPort(port[1:])
+}
+
+// isPort reports whether s is a port number between 1 and 65535.
+func isPort(s string) bool {
+	if len(s) == 0 || len(s) > 5 || s[0] == '0' {
+		return false
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+		n = n*10 + int(s[i]-'0')
+	}
+	return n <= 65535
+}
@@ -0,0 +1,119 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func TestIsLocalhostOrigin(t *testing.T) {
+	for _, origin := range []string{
+		"http://localhost",
+		"http://localhost:3000",
+		"https://localhost:5173",
+		"http://LOCALHOST:8080",
+		"http://127.0.0.1:8080",
+		"http://127.1.2.3",
+		"https://127.255.255.255:65535",
+		"http://[::1]",
+		"http://[::1]:3000",
+	} {
+		assert.True(t, isLocalhostOrigin(origin))
+	}
+	for _, origin := range []string{
+		"ws://localhost:3000",
+		"http://localhost.evil.com",
+		"http://localhost:",
+		"http://localhost:0",
+		"http://localhost:65536",
+		"http://localhost:03000",
+		"http://localhost:3000/",
+		"http://localhost@evil.com",
+		"http://128.0.0.1",
+		"http://127.0.0.1.evil.com",
+		"http://[::2]:3000",
+		"http://[::ffff:127.0.0.1]",
+		"http://[::1",
+		"http://::1",
+		"http://evil.com:3000",
+	} {
+		assert.False(t, isLocalhostOrigin(origin))
+	}
+}
+
+func TestAllowLocalhost(t *testing.T) {
+	var buf bytes.Buffer
+	hlog.SetOutput(&buf)
+	defer hlog.SetOutput(os.Stderr)
+
+	router := newTestRouter(Config{
+		AllowOrigins:   []string{"https://google.com"},
+		AllowLocalhost: true,
+		DenyOrigins:    []string{"http://localhost:6666"},
+	})
+	assert.True(t, strings.Contains(buf.String(), "AllowLocalhost is enabled"))
+
+	w := performRequest(router, "GET", "http://localhost:5173")
+	assert.DeepEqual(t, "http://localhost:5173", w.Header().Get("Access-Control-Allow-Origin"))
+	w = performRequest(router, "GET", "http://[::1]:3000")
+	assert.DeepEqual(t, "http://[::1]:3000", w.Header().Get("Access-Control-Allow-Origin"))
+	w = performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, "https://google.com", w.Header().Get("Access-Control-Allow-Origin"))
+	w = performRequest(router, "GET", "http://localhost:6666")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+	w = performRequest(router, "GET", "http://192.168.1.2:3000")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+
+	// AllowLocalhost is enough to allow origins
+	assert.Nil(t, Config{AllowLocalhost: true}.Validate())
+	assert.NotNil(t, Config{AllowLocalhost: true, AllowAllOrigins: true}.Validate())
+	assert.False(t, DefaultConfig().AllowLocalhost)
+}
+
+func TestWithLocalhost(t *testing.T) {
+	var buf bytes.Buffer
+	hlog.SetOutput(&buf)
+	defer hlog.SetOutput(os.Stderr)
+
+	router := newOptionsRouter(WithAllOrigins(), WithLocalhost())
+	w := performRequest(router, "GET", "http://127.0.0.1:8000")
+	assert.DeepEqual(t, "http://127.0.0.1:8000", w.Header().Get("Access-Control-Allow-Origin"))
+	w = performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+
+	router = newOptionsRouter(WithLocalhost(), WithAllOrigins())
+	w = performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,161 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import "strings"
+
+// originMatcher is a compiled set of origin rules. Exact origins are looked up in a hash
+// set and subdomain rules like https://*.example.com in a trie of reversed host labels,
+// so the lookup time does not grow with the number of rules. Rules with a port range
+// are looked up by schema and host, and their port is checked numerically. Other
+// wildcard rules are matched one by one.
+type originMatcher struct {
+	exact map[string]struct{}
+	// subdomains maps a schema like "https://" to its trie, "" matches any schema
+	subdomains map[string]*labelTrie
+	// ports maps a schema and host like "https://example.com" to its port ranges
+	ports     map[string][]portRange
+	wildcards [][]string
+}
+
+func newOriginMatcher(origins []string, wildcardRules [][]string) *originMatcher {
+	m := &originMatcher{
+		exact:      make(map[string]struct{}, len(origins)),
+		subdomains: make(map[string]*labelTrie),
+	}
+	for _, o := range origins {
+		if key, r, ok, _ := parsePortRule(o); ok {
+			if m.ports == nil {
+				m.ports = make(map[string][]portRange)
+			}
+			m.ports[key] = append(m.ports[key], r)
+			continue
+		}
+		if !strings.Contains(o, "*") {
+			m.exact[o] = struct{}{}
+		}
+	}
+	for _, w := range wildcardRules {
+		schema, domain, ok := parseSubdomainRule(w)
+		if !ok {
+			m.wildcards = append(m.wildcards, w)
+			continue
+		}
+		t, ok := m.subdomains[schema]
+		if !ok {
+			t = &labelTrie{}
+			m.subdomains[schema] = t
+		}
+		t.insert(domain)
+	}
+	return m
+}
+
+// parseSubdomainRule returns the schema and the domain of a wildcard rule that matches
+// all the subdomains of a domain, like {"https://", ".example.com"} or {"*", ".example.com"}.
+func parseSubdomainRule(w []string) (schema, domain string, ok bool) {
+	prefix, suffix := w[0], w[1]
+	if len(suffix) < 2 || suffix[0] != '.' || strings.ContainsAny(suffix, "*/") {
+		return "", "", false
+	}
+	switch {
+	case prefix == "*":
+		return "", suffix[1:], true
+	case strings.HasSuffix(prefix, "://") && strings.Index(prefix, "://") == len(prefix)-3:
+		return prefix, suffix[1:], true
+	}
+	return "", "", false
+}
+
+func (m *originMatcher) empty() bool {
+	return len(m.exact) == 0 && len(m.subdomains) == 0 && len(m.ports) == 0 && len(m.wildcards) == 0
+}
+
+func (m *originMatcher) match(origin string) bool {
+	if _, ok := m.exact[origin]; ok {
+		return true
+	}
+	if len(m.subdomains) > 0 {
+		schema, host := origin[:0], origin
+		if i := strings.Index(origin, "://"); i >= 0 {
+			schema, host = origin[:i+3], origin[i+3:]
+		}
+		if t, ok := m.subdomains[schema]; ok && t.matchSubdomain(host) {
+			return true
+		}
+		if t, ok := m.subdomains[""]; ok && t.matchSubdomain(host) {
+			return true
+		}
+	}
+	if len(m.ports) > 0 && m.matchPort(origin) {
+		return true
+	}
+	return len(m.wildcards) > 0 && matchWildcardOrigin(m.wildcards, origin)
+}
+
+// labelTrie is a trie of reversed host labels, com -> example -> api.
+type labelTrie struct {
+	children map[string]*labelTrie
+	// subdomains is set if all the subdomains of the labels down to this node match
+	subdomains bool
+}
+
+func (t *labelTrie) insert(domain string) {
+	node := t
+	for end := len(domain); end > 0; {
+		i := strings.LastIndexByte(domain[:end], '.')
+		label := domain[i+1 : end]
+		if node.children == nil {
+			node.children = make(map[string]*labelTrie)
+		}
+		child, ok := node.children[label]
+		if !ok {
+			child = &labelTrie{}
+			node.children[label] = child
+		}
+		node = child
+		end = i
+	}
+	node.subdomains = true
+}
+
+// matchSubdomain reports whether the host is a subdomain of a domain of the trie.
+func (t *labelTrie) matchSubdomain(host string) bool {
+	node := t
+	for end := len(host); end > 0; {
+		i := strings.LastIndexByte(host[:end], '.')
+		if node = node.children[host[i+1:end]]; node == nil || i < 0 {
+			return false
+		}
+		if node.subdomains {
+			return true
+		}
+		end = i
+	}
+	return false
+}
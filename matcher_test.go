@@ -0,0 +1,113 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestOriginMatcher(t *testing.T) {
+	origins := []string{
+		"https://google.com",
+		"https://*.github.com",
+		"https://*.api.example.com",
+		"*.golang.org",
+		"https://api.*",
+		"http://some.*.subdomain.com",
+	}
+	m := newOriginMatcher(origins, parseWildcardOrigins(origins))
+	assert.DeepEqual(t, 2, len(m.wildcards))
+
+	for _, origin := range []string{
+		"https://google.com",
+		"https://gist.github.com",
+		"https://a.b.github.com",
+		"https://v1.api.example.com",
+		"http://something.golang.org",
+		"https://something.golang.org",
+		"https://api.facebook.com",
+		"http://some.test.subdomain.com",
+	} {
+		assert.True(t, m.match(origin))
+		assert.True(t, matchWildcardOrigin(parseWildcardOrigins(origins), origin) || origin == "https://google.com")
+	}
+	for _, origin := range []string{
+		"http://google.com",
+		"https://github.com",
+		"http://gist.github.com",
+		"https://gist.github.com.evil.com",
+		"https://example.com",
+		"https://golang.org",
+		"https://go.org",
+		"http://other.test.subdomain.com",
+	} {
+		assert.False(t, m.match(origin))
+	}
+
+	assert.True(t, newOriginMatcher(nil, nil).empty())
+	assert.False(t, newOriginMatcher(nil, nil).match("https://google.com"))
+}
+
+func newBenchmarkMatcher(n int) *originMatcher {
+	origins := make([]string, 0, 2*n)
+	for i := 0; i < n; i++ {
+		origins = append(origins, "https://customer"+strconv.Itoa(i)+".com")
+		origins = append(origins, "https://*.tenant"+strconv.Itoa(i)+".example.com")
+	}
+	return newOriginMatcher(origins, parseWildcardOrigins(origins))
+}
+
+func benchmarkOriginMatcher(b *testing.B, n int) {
+	m := newBenchmarkMatcher(n)
+	exact := "https://customer" + strconv.Itoa(n-1) + ".com"
+	subdomain := "https://app.tenant" + strconv.Itoa(n-1) + ".example.com"
+	miss := "https://unknown.example.org"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !m.match(exact) || !m.match(subdomain) || m.match(miss) {
+			b.Fatal("unexpected match result")
+		}
+	}
+}
+
+func Benchmark_origin_matcher_10(b *testing.B) {
+	benchmarkOriginMatcher(b, 10)
+}
+
+func Benchmark_origin_matcher_1000(b *testing.B) {
+	benchmarkOriginMatcher(b, 1000)
+}
+
+func Benchmark_origin_matcher_10000(b *testing.B) {
+	benchmarkOriginMatcher(b, 10000)
+}
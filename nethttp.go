@@ -0,0 +1,191 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// NewHTTP generates a net/http middleware from the config. It compiles the same policy as
+// New and makes the same decisions, so that Hertz and net/http handlers mixed in a service
+// behave the same.
+//
+// The per-request helpers ExposeHeaders, AllowHeaders and SetMaxAge only work with Hertz,
+// and preflight requests are always answered by the middleware.
+func NewHTTP(config Config) func(http.Handler) http.Handler {
+	cors := newCors(config)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cors.serveHTTP(w, r, next)
+		})
+	}
+}
+
+var contextPool = sync.Pool{
+	New: func() interface{} {
+		return app.NewContext(0)
+	},
+}
+
+// serveHTTP runs the policy on a RequestContext holding the headers of the request, and
+// copies the headers of its response to w.
+func (cors *cors) serveHTTP(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	c := contextPool.Get().(*app.RequestContext)
+	defer func() {
+		c.Reset()
+		contextPool.Put(c)
+	}()
+	c.Request.Header.SetMethod(r.Method)
+	c.Request.SetRequestURI(r.URL.RequestURI())
+	c.Request.Header.SetHost(r.Host)
+	for key, values := range r.Header {
+		for _, v := range values {
+			c.Request.Header.Add(key, v)
+		}
+	}
+	ip := remoteIP(r)
+	c.SetClientIPFunc(func(*app.RequestContext) string {
+		return ip
+	})
+
+	request := cors.applyCors(c)
+	header := w.Header()
+	c.Response.Header.VisitAll(func(key, value []byte) {
+		switch string(key) {
+		case consts.HeaderContentType, consts.HeaderContentLength, consts.HeaderServer:
+			return
+		}
+		// keep the headers set by the outer middleware, like Vary: Accept-Encoding
+		if k := string(key); k == "Vary" {
+			header.Set(k, mergeList(strings.Join(header.Values(k), ","), strings.Split(string(value), ","), normalizeHeaderKey))
+		} else {
+			header.Add(k, string(value))
+		}
+	})
+
+	switch {
+	case c.IsAborted():
+		w.WriteHeader(c.Response.StatusCode())
+	case request == preflightRequest:
+		w.WriteHeader(consts.StatusNoContent)
+	case request == actualRequest && cors.dynamicExpose:
+		next.ServeHTTP(&exposeWriter{ResponseWriter: w, cors: cors}, r)
+	default:
+		next.ServeHTTP(w, r)
+	}
+}
+
+// remoteIP is the IP of the client, used by the preflight rate limit like
+// RequestContext.ClientIP.
+func remoteIP(r *http.Request) string {
+	if v := r.Header.Get("X-Forwarded-For"); len(v) > 0 {
+		return strings.TrimSpace(strings.SplitN(v, ",", 2)[0])
+	}
+	if v := r.Header.Get("X-Real-IP"); len(v) > 0 {
+		return strings.TrimSpace(v)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// exposeWriter adds the headers set by the handler to Access-Control-Expose-Headers
+// before they are written.
+type exposeWriter struct {
+	http.ResponseWriter
+	cors        *cors
+	wroteHeader bool
+}
+
+func (w *exposeWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		header := w.Header()
+		var exposed []string
+		for key := range header {
+			if k := strings.ToLower(key); w.cors.isDynamicExposable(k) {
+				exposed = append(exposed, k)
+			}
+		}
+		if len(exposed) > 0 {
+			sort.Strings(exposed)
+			header.Set("Access-Control-Expose-Headers", mergeList(header.Get("Access-Control-Expose-Headers"), exposed, normalizeHeaderKey))
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *exposeWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher for streaming handlers.
+func (w *exposeWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker for WebSocket and other upgrade handlers.
+func (w *exposeWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	return h.Hijack()
+}
+
+// Push implements http.Pusher for HTTP/2 server push.
+func (w *exposeWriter) Push(target string, opts *http.PushOptions) error {
+	p, ok := w.ResponseWriter.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+	return p.Push(target, opts)
+}
+
+// Unwrap returns the wrapped ResponseWriter for http.ResponseController.
+func (w *exposeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
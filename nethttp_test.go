@@ -0,0 +1,367 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+// sharedCase is run against both the Hertz and the net/http middleware, which must
+// answer the same.
+type sharedCase struct {
+	name    string
+	config  Config
+	method  string
+	path    string
+	origin  string
+	headers map[string]string
+	status  int
+	// expected response headers, an empty value means the header is not set
+	expected map[string]string
+}
+
+var sharedConfig = Config{
+	AllowOrigins:     []string{"https://google.com", "https://*.github.com"},
+	AllowMethods:     []string{"GET", "PUT"},
+	AllowHeaders:     []string{"Content-Type", "X-Token"},
+	ExposeHeaders:    []string{"X-Total"},
+	AllowCredentials: true,
+	AllowWildcard:    true,
+	MaxAge:           time.Hour,
+	DenyOrigins:      []string{"https://evil.github.com"},
+}
+
+func withConfig(f func(c *Config)) Config {
+	c := sharedConfig
+	f(&c)
+	return c
+}
+
+var sharedCases = []sharedCase{
+	{
+		name:   "allowed actual request",
+		config: sharedConfig,
+		method: "GET",
+		origin: "https://google.com",
+		status: http.StatusOK,
+		expected: map[string]string{
+			"Access-Control-Allow-Origin":      "https://google.com",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Expose-Headers":    "X-Total",
+			"Vary":                             "Origin",
+		},
+	},
+	{
+		name:   "allowed wildcard origin",
+		config: sharedConfig,
+		method: "GET",
+		origin: "https://api.github.com",
+		status: http.StatusOK,
+		expected: map[string]string{
+			"Access-Control-Allow-Origin": "https://api.github.com",
+		},
+	},
+	{
+		name:     "rejected origin",
+		config:   sharedConfig,
+		method:   "GET",
+		origin:   "https://example.org",
+		status:   http.StatusForbidden,
+		expected: map[string]string{"Access-Control-Allow-Origin": ""},
+	},
+	{
+		name:     "denied origin",
+		config:   sharedConfig,
+		method:   "GET",
+		origin:   "https://evil.github.com",
+		status:   http.StatusForbidden,
+		expected: map[string]string{"Access-Control-Allow-Origin": ""},
+	},
+	{
+		name:     "no origin",
+		config:   sharedConfig,
+		method:   "GET",
+		status:   http.StatusOK,
+		expected: map[string]string{"Access-Control-Allow-Origin": ""},
+	},
+	{
+		name:     "same origin",
+		config:   sharedConfig,
+		method:   "GET",
+		origin:   "http://example.com",
+		status:   http.StatusOK,
+		expected: map[string]string{"Access-Control-Allow-Origin": ""},
+	},
+	{
+		name:    "preflight",
+		config:  sharedConfig,
+		method:  "OPTIONS",
+		origin:  "https://google.com",
+		headers: map[string]string{"Access-Control-Request-Method": "PUT"},
+		status:  http.StatusNoContent,
+		expected: map[string]string{
+			"Access-Control-Allow-Origin":      "https://google.com",
+			"Access-Control-Allow-Credentials": "true",
+			"Access-Control-Allow-Methods":     "GET,PUT",
+			"Access-Control-Allow-Headers":     "Content-Type,X-Token",
+			"Access-Control-Max-Age":           "3600",
+			"Access-Control-Expose-Headers":    "",
+		},
+	},
+	{
+		name:     "rejected preflight",
+		config:   sharedConfig,
+		method:   "OPTIONS",
+		origin:   "https://example.org",
+		headers:  map[string]string{"Access-Control-Request-Method": "PUT"},
+		status:   http.StatusForbidden,
+		expected: map[string]string{"Access-Control-Allow-Methods": ""},
+	},
+	{
+		name: "null origin",
+		config: withConfig(func(c *Config) {
+			c.AllowNullOrigin = true
+		}),
+		method: "GET",
+		origin: "null",
+		status: http.StatusOK,
+		expected: map[string]string{
+			"Access-Control-Allow-Origin":      "null",
+			"Access-Control-Allow-Credentials": "",
+		},
+	},
+	{
+		name: "all origins",
+		config: Config{
+			AllowAllOrigins: true,
+			AllowTiming:     true,
+		},
+		method: "GET",
+		origin: "https://example.org",
+		status: http.StatusOK,
+		expected: map[string]string{
+			"Access-Control-Allow-Origin": "*",
+			"Timing-Allow-Origin":         "*",
+			"Vary":                        "",
+		},
+	},
+	{
+		name: "dynamic expose headers",
+		config: withConfig(func(c *Config) {
+			c.DynamicExposeHeaders = true
+		}),
+		method: "GET",
+		origin: "https://google.com",
+		status: http.StatusOK,
+		expected: map[string]string{
+			"Access-Control-Expose-Headers": "X-Total,X-Request-Id",
+		},
+	},
+	{
+		name: "cross-site request with resource isolation",
+		config: withConfig(func(c *Config) {
+			c.ResourceIsolation = true
+			c.ResourceIsolationAllowPaths = []string{"/public"}
+			c.CrossOriginOpenerPolicy = "same-origin"
+		}),
+		method:  "GET",
+		origin:  "https://example.org",
+		headers: map[string]string{"Sec-Fetch-Site": "cross-site", "Sec-Fetch-Mode": "cors"},
+		status:  http.StatusForbidden,
+		expected: map[string]string{
+			"Cross-Origin-Opener-Policy": "same-origin",
+		},
+	},
+	{
+		name: "allowlisted path with resource isolation",
+		config: withConfig(func(c *Config) {
+			c.ResourceIsolation = true
+			c.ResourceIsolationAllowPaths = []string{"/public"}
+		}),
+		method:   "GET",
+		path:     "/public",
+		headers:  map[string]string{"Sec-Fetch-Site": "cross-site", "Sec-Fetch-Mode": "no-cors"},
+		status:   http.StatusOK,
+		expected: map[string]string{"Access-Control-Allow-Origin": ""},
+	},
+	{
+		name: "timing only origin",
+		config: withConfig(func(c *Config) {
+			c.AllowTiming = true
+			c.TimingAllowOrigins = []string{"https://cdn.com"}
+		}),
+		method: "GET",
+		origin: "https://cdn.com",
+		status: http.StatusOK,
+		expected: map[string]string{
+			"Access-Control-Allow-Origin": "",
+			"Timing-Allow-Origin":         "https://cdn.com",
+		},
+	},
+}
+
+func sharedHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Request-Id", "1")
+	_, _ = w.Write([]byte("ok"))
+}
+
+func checkSharedCase(t *testing.T, c *sharedCase, status int, header func(key string) string) {
+	assert.DeepEqual(t, c.status, status)
+	for key, value := range c.expected {
+		assert.DeepEqual(t, value, header(key))
+	}
+}
+
+func TestSharedCasesHertz(t *testing.T) {
+	for i := range sharedCases {
+		c := &sharedCases[i]
+		t.Run(c.name, func(t *testing.T) {
+			router := route.NewEngine(config.NewOptions([]config.Option{}))
+			router.Use(New(c.config))
+			router.Any("/*path", func(_ context.Context, rc *app.RequestContext) {
+				rc.Header("X-Request-Id", "1")
+				rc.String(http.StatusOK, "ok")
+			})
+			headers := []ut.Header{{Key: "Host", Value: "example.com"}}
+			for key, value := range c.headers {
+				headers = append(headers, ut.Header{Key: key, Value: value})
+			}
+			path := c.path
+			if len(path) == 0 {
+				path = "/"
+			}
+			w := performRequestPath(router, c.method, path, c.origin, headers...)
+			checkSharedCase(t, c, w.Code, w.Header().Get)
+		})
+	}
+}
+
+func TestSharedCasesHTTP(t *testing.T) {
+	for i := range sharedCases {
+		c := &sharedCases[i]
+		t.Run(c.name, func(t *testing.T) {
+			handler := NewHTTP(c.config)(http.HandlerFunc(sharedHandler))
+			path := c.path
+			if len(path) == 0 {
+				path = "/"
+			}
+			r := httptest.NewRequest(c.method, "http://example.com"+path, nil)
+			if len(c.origin) > 0 {
+				r.Header.Set("Origin", c.origin)
+			}
+			for key, value := range c.headers {
+				r.Header.Set(key, value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			checkSharedCase(t, c, w.Code, w.Header().Get)
+		})
+	}
+}
+
+func TestNewHTTPPreflightRateLimit(t *testing.T) {
+	handler := NewHTTP(Config{
+		AllowOrigins:             []string{"https://google.com"},
+		PreflightOriginRateLimit: 1,
+	})(http.HandlerFunc(sharedHandler))
+
+	preflight := func(ip string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("OPTIONS", "http://example.com/", nil)
+		r.RemoteAddr = ip + ":1234"
+		r.Header.Set("Origin", "https://google.com")
+		r.Header.Set("Access-Control-Request-Method", "GET")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+	assert.DeepEqual(t, http.StatusNoContent, preflight("10.0.0.1").Code)
+	w := preflight("10.0.0.2")
+	assert.DeepEqual(t, http.StatusTooManyRequests, w.Code)
+	assert.DeepEqual(t, "1", w.Header().Get("Retry-After"))
+}
+
+func TestNewHTTPKeepsOuterHeaders(t *testing.T) {
+	handler := NewHTTP(Config{
+		AllowOrigins: []string{"https://google.com"},
+	})(http.HandlerFunc(sharedHandler))
+
+	r := httptest.NewRequest("GET", "http://example.com/", nil)
+	r.Header.Set("Origin", "https://google.com")
+	w := httptest.NewRecorder()
+	// set by an outer gzip middleware
+	w.Header().Set("Vary", "Accept-Encoding")
+	handler.ServeHTTP(w, r)
+	assert.DeepEqual(t, "Accept-Encoding,Origin", w.Header().Get("Vary"))
+	assert.DeepEqual(t, "https://google.com", w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+// hijackRecorder is a ResponseRecorder implementing http.Hijacker.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (w *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return nil, nil, nil
+}
+
+func TestNewHTTPHijack(t *testing.T) {
+	var err error
+	handler := NewHTTP(Config{
+		AllowOrigins:         []string{"https://google.com"},
+		DynamicExposeHeaders: true,
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, ok := w.(http.Hijacker)
+		assert.True(t, ok)
+		_, _, err = h.Hijack()
+	}))
+
+	r := httptest.NewRequest("GET", "http://example.com/", nil)
+	r.Header.Set("Origin", "https://google.com")
+	w := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	handler.ServeHTTP(w, r)
+	assert.Nil(t, err)
+	assert.True(t, w.hijacked)
+
+	// the wrapped writer does not support hijacking
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	assert.DeepEqual(t, http.ErrNotSupported, err)
+}
@@ -0,0 +1,324 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// Option configures a policy built by NewWithOptions. Options can only be built by the
+// functions of this package, and each one sets a feature with all its settings, so
+// that no option depends on another one being set.
+type Option struct {
+	apply func(c *Config)
+}
+
+// NewWithOptions generates a middleware from the options. It compiles to the same
+// policy as New, but conflicting settings can not be combined: an option replaces the
+// settings of the previous ones it conflicts with. Without any origin option, no
+// cross-origin request is allowed.
+//
+// The options panic on a bad value, like an origin without a schema, and NewWithOptions
+// panics on the combinations which can not be replaced, like WithAllOrigins with
+// WithCredentials, or a pattern over a public suffix without WithPublicSuffixWildcard.
+func NewWithOptions(opts ...Option) app.HandlerFunc {
+	config := Config{}
+	for _, opt := range opts {
+		opt.apply(&config)
+	}
+	if config.AllowAllOrigins && config.AllowCredentials {
+		panic("cors: WithAllOrigins can not be used with WithCredentials, browsers reject \"*\" with credentials")
+	}
+	if err := config.Validate(); err != nil && err != errAllOriginsDisabled {
+		panic(err.Error())
+	}
+	return compileCors(config).handle
+}
+
+// validateOptionOrigins panics on a bad origin value of an option. The browser extension
+// and file schemas are accepted, see enableOriginSchemas. The WebSocket schemas are not,
+// browsers send the origin of the page in WebSocket handshakes.
+func validateOptionOrigins(c Config) {
+	c.AllowLocalhost = true
+	c.AllowBrowserExtensions = true
+	c.AllowFiles = true
+	if err := c.Validate(); err != nil {
+		panic(err.Error())
+	}
+}
+
+// enableOriginSchemas enables the schemas of the origins which are not allowed by default,
+// like chrome-extension:// or file://.
+func enableOriginSchemas(c *Config, origins []string) {
+	for _, origin := range origins {
+		origin = strings.ToLower(origin)
+		for _, schema := range ExtensionSchemas {
+			if strings.HasPrefix(origin, schema) {
+				c.AllowBrowserExtensions = true
+			}
+		}
+		for _, schema := range FileSchemas {
+			if strings.HasPrefix(origin, schema) {
+				c.AllowFiles = true
+			}
+		}
+	}
+}
+
+// validateRateLimit panics on a bad rate limit of an option.
+func validateRateLimit(rate float64, burst int) {
+	if rate <= 0 || burst < 0 {
+		panic("cors: bad preflight rate limit: rate must be positive and burst must not be negative")
+	}
+}
+
+// WithOrigins allows the origins, like "https://foo.com". It replaces WithAllOrigins.
+func WithOrigins(origins ...string) Option {
+	for _, origin := range origins {
+		if strings.Contains(origin, "*") {
+			panic("cors: bad origin " + origin + ": use WithOriginPattern or WithAllOrigins")
+		}
+		if origin == nullOrigin {
+			panic("cors: bad origin null: use WithNullOrigin")
+		}
+	}
+	validateOptionOrigins(Config{AllowOrigins: origins})
+	return Option{apply: func(c *Config) {
+		c.AllowAllOrigins = false
+		c.AllowOrigins = append(c.AllowOrigins, origins...)
+		enableOriginSchemas(c, origins)
+	}}
+}
+
+// WithOriginPattern allows the origins matching the patterns, like "https://*.foo.com"
+// or "https://api.*". It replaces WithAllOrigins.
+func WithOriginPattern(patterns ...string) Option {
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			panic("cors: bad origin pattern *: use WithAllOrigins")
+		}
+	}
+	validateOptionOrigins(Config{AllowOrigins: patterns, AllowWildcard: true, AllowPublicSuffixWildcard: true})
+	return Option{apply: func(c *Config) {
+		c.AllowAllOrigins = false
+		c.AllowWildcard = true
+		c.AllowOrigins = append(c.AllowOrigins, patterns...)
+		enableOriginSchemas(c, patterns)
+	}}
+}
+
+// WithPublicSuffixWildcard allows the patterns matching every domain under a public
+// suffix, like "https://*.github.io", see Config.AllowPublicSuffixWildcard.
+func WithPublicSuffixWildcard() Option {
+	return Option{apply: func(c *Config) {
+		c.AllowAllOrigins = false
+		c.AllowWildcard = true
+		c.AllowPublicSuffixWildcard = true
+	}}
+}
+
+// WithOriginFunc allows the origins for which f returns true. It replaces WithAllOrigins
+// and the previous origin functions.
+func WithOriginFunc(f func(origin string) bool) Option {
+	return WithCachedOriginFunc(f, nil)
+}
+
+// WithCachedOriginFunc is like WithOriginFunc, the decisions of f are cached in cache.
+func WithCachedOriginFunc(f func(origin string) bool, cache *OriginCache) Option {
+	return Option{apply: func(c *Config) {
+		c.AllowAllOrigins = false
+		c.AllowOriginFunc = f
+		c.AllowOriginFuncCache = cache
+	}}
+}
+
+// WithAllOrigins allows all origins with "*". It replaces the previous origin options,
+// and can not be used with WithCredentials.
+func WithAllOrigins() Option {
+	return Option{apply: func(c *Config) {
+		c.AllowAllOrigins = true
+		c.AllowOrigins = nil
+		c.AllowWildcard = false
+		c.AllowPublicSuffixWildcard = false
+		c.AllowOriginFunc = nil
+		c.AllowOriginFuncCache = nil
+		c.AllowLocalhost = false
+		c.AllowOriginCIDRs = nil
+	}}
+}
+
+// WithOriginCIDRs allows the origins whose host is an IP address of the CIDR blocks, see
+// Config.AllowOriginCIDRs. It replaces WithAllOrigins.
+func WithOriginCIDRs(rules ...string) Option {
+	validateOptionOrigins(Config{AllowOriginCIDRs: rules})
+	return Option{apply: func(c *Config) {
+		c.AllowAllOrigins = false
+		c.AllowOriginCIDRs = append(c.AllowOriginCIDRs, rules...)
+		enableOriginSchemas(c, rules)
+	}}
+}
+
+// WithLocalhost allows the http and https origins of localhost, 127.0.0.0/8 and [::1] on
+// any port, for development servers, see Config.AllowLocalhost. It replaces WithAllOrigins.
+func WithLocalhost() Option {
+	return Option{apply: func(c *Config) {
+		c.AllowAllOrigins = false
+		c.AllowLocalhost = true
+	}}
+}
+
+// WithNullOrigin allows the null origin, with credentials or not. A zero maxAge sends
+// no Access-Control-Max-Age, so its preflight requests are not cached, see
+// Config.NullOriginMaxAge.
+func WithNullOrigin(credentials bool, maxAge time.Duration) Option {
+	return Option{apply: func(c *Config) {
+		c.AllowNullOrigin = true
+		c.AllowNullOriginCredentials = credentials
+		c.NullOriginMaxAge = maxAge
+	}}
+}
+
+// WithDenyOrigins denies the origins before the allow rules are checked, they may
+// contain one "*" like "https://*.foo.com".
+func WithDenyOrigins(origins ...string) Option {
+	validateOptionOrigins(Config{DenyOrigins: origins})
+	return Option{apply: func(c *Config) {
+		c.DenyOrigins = append(c.DenyOrigins, origins...)
+		enableOriginSchemas(c, origins)
+	}}
+}
+
+// WithMethods allows the methods.
+func WithMethods(methods ...string) Option {
+	return Option{apply: func(c *Config) {
+		c.AllowMethods = append(c.AllowMethods, methods...)
+	}}
+}
+
+// WithHeaders allows the request headers.
+func WithHeaders(headers ...string) Option {
+	return Option{apply: func(c *Config) {
+		c.AllowHeaders = append(c.AllowHeaders, headers...)
+	}}
+}
+
+// WithExposeHeaders exposes the response headers.
+func WithExposeHeaders(headers ...string) Option {
+	return Option{apply: func(c *Config) {
+		c.ExposeHeaders = append(c.ExposeHeaders, headers...)
+	}}
+}
+
+// WithDynamicExposeHeaders exposes the response headers set by the handlers, filtered by
+// the allow and deny rules, see Config.DynamicExposeHeaders.
+func WithDynamicExposeHeaders(allow, deny []string) Option {
+	return Option{apply: func(c *Config) {
+		c.DynamicExposeHeaders = true
+		c.DynamicExposeAllow = allow
+		c.DynamicExposeDeny = deny
+	}}
+}
+
+// WithCredentials allows requests with credentials. It can not be used with WithAllOrigins.
+func WithCredentials() Option {
+	return Option{apply: func(c *Config) {
+		c.AllowCredentials = true
+	}}
+}
+
+// WithMaxAge sets how long the result of a preflight request can be cached.
+func WithMaxAge(maxAge time.Duration) Option {
+	return Option{apply: func(c *Config) {
+		c.MaxAge = maxAge
+	}}
+}
+
+// WithPreflightHandlers runs the OPTIONS handler of the route on preflight requests, see
+// Config.PreflightHandlers.
+func WithPreflightHandlers() Option {
+	return Option{apply: func(c *Config) {
+		c.PreflightHandlers = true
+	}}
+}
+
+// WithPreflightOriginRateLimit limits the preflight requests per second of each origin,
+// shared by all its clients. A zero burst defaults to the rate rounded up.
+func WithPreflightOriginRateLimit(rate float64, burst int) Option {
+	validateRateLimit(rate, burst)
+	return Option{apply: func(c *Config) {
+		c.PreflightOriginRateLimit = rate
+		c.PreflightOriginBurst = burst
+	}}
+}
+
+// WithPreflightClientRateLimit limits the preflight requests per second of each client
+// IP. A zero burst defaults to the rate rounded up.
+func WithPreflightClientRateLimit(rate float64, burst int) Option {
+	validateRateLimit(rate, burst)
+	return Option{apply: func(c *Config) {
+		c.PreflightClientRateLimit = rate
+		c.PreflightClientBurst = burst
+	}}
+}
+
+// WithTiming sends Timing-Allow-Origin to the allowed origins, and to the timingOrigins
+// which only get access to resource timing.
+func WithTiming(timingOrigins ...string) Option {
+	validateOptionOrigins(Config{TimingAllowOrigins: timingOrigins})
+	return Option{apply: func(c *Config) {
+		c.AllowTiming = true
+		c.TimingAllowOrigins = append(c.TimingAllowOrigins, timingOrigins...)
+		enableOriginSchemas(c, timingOrigins)
+	}}
+}
+
+// WithResourceIsolation rejects cross-site requests which do not come from an allowed
+// origin, except on allowPaths, see Config.ResourceIsolation.
+func WithResourceIsolation(allowPaths ...string) Option {
+	return Option{apply: func(c *Config) {
+		c.ResourceIsolation = true
+		c.ResourceIsolationAllowPaths = append(c.ResourceIsolationAllowPaths, allowPaths...)
+	}}
+}
+
+// WithBrowserExtensions allows the origins of browser extensions.
+func WithBrowserExtensions() Option {
+	return Option{apply: func(c *Config) {
+		c.AllowBrowserExtensions = true
+	}}
+}
+
+// WithWebSockets enforces the origin rules on WebSocket handshakes, see Config.AllowWebSockets.
+func WithWebSockets() Option {
+	return Option{apply: func(c *Config) {
+		c.AllowWebSockets = true
+	}}
+}
@@ -0,0 +1,211 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func newOptionsRouter(opts ...Option) *route.Engine {
+	return newHandlerRouter(NewWithOptions(opts...))
+}
+
+func newHandlerRouter(handler app.HandlerFunc) *route.Engine {
+	router := route.NewEngine(config.NewOptions([]config.Option{}))
+	router.Use(handler)
+	router.Any("/", func(ctx context.Context, c *app.RequestContext) {
+		c.String(consts.StatusOK, "ok")
+	})
+	return router
+}
+
+func TestNewWithOptions(t *testing.T) {
+	router := newOptionsRouter(
+		WithOrigins("https://google.com"),
+		WithOriginPattern("https://*.github.com"),
+		WithMethods("GET", "PUT"),
+		WithHeaders("Content-Type"),
+		WithExposeHeaders("X-Total"),
+		WithCredentials(),
+		WithMaxAge(time.Hour),
+	)
+
+	w := performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, "https://google.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.DeepEqual(t, "X-Total", w.Header().Get("Access-Control-Expose-Headers"))
+
+	w = performRequest(router, "OPTIONS", "https://api.github.com")
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	assert.DeepEqual(t, "https://api.github.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "GET,PUT", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.DeepEqual(t, "3600", w.Header().Get("Access-Control-Max-Age"))
+
+	w = performRequest(router, "GET", "https://example.com")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+}
+
+func TestNewWithOptionsSameAsNew(t *testing.T) {
+	c := Config{
+		AllowOrigins:     []string{"https://google.com"},
+		AllowMethods:     []string{"GET", "PUT"},
+		AllowHeaders:     []string{"Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+		AllowNullOrigin:  true,
+	}
+	fromConfig := newHandlerRouter(New(c))
+	fromOptions := newOptionsRouter(
+		WithOrigins("https://google.com"),
+		WithMethods("GET", "PUT"),
+		WithHeaders("Content-Type"),
+		WithCredentials(),
+		WithMaxAge(time.Hour),
+		WithNullOrigin(false, 0),
+	)
+	for _, origin := range []string{"https://google.com", "https://example.com", "null", ""} {
+		for _, method := range []string{"GET", "OPTIONS"} {
+			w1 := performRequest(fromConfig, method, origin)
+			w2 := performRequest(fromOptions, method, origin)
+			assert.DeepEqual(t, w1.Code, w2.Code)
+			assert.DeepEqual(t, w1.Header().Get("Access-Control-Allow-Origin"), w2.Header().Get("Access-Control-Allow-Origin"))
+			assert.DeepEqual(t, w1.Header().Get("Access-Control-Allow-Credentials"), w2.Header().Get("Access-Control-Allow-Credentials"))
+			assert.DeepEqual(t, w1.Header().Get("Access-Control-Allow-Methods"), w2.Header().Get("Access-Control-Allow-Methods"))
+		}
+	}
+}
+
+func TestNewWithOptionsConflicts(t *testing.T) {
+	// WithAllOrigins replaces the previous origin options
+	router := newOptionsRouter(WithOrigins("https://google.com"), WithOriginFunc(func(string) bool { return false }), WithAllOrigins())
+	w := performRequest(router, "GET", "https://example.com")
+	assert.DeepEqual(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+
+	// and the next origin options replace WithAllOrigins
+	router = newOptionsRouter(WithAllOrigins(), WithOrigins("https://google.com"))
+	w = performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, "https://google.com", w.Header().Get("Access-Control-Allow-Origin"))
+	w = performRequest(router, "GET", "https://example.com")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+
+	// the cache can only be set with its function
+	cache := NewOriginCache(10, time.Minute)
+	router = newOptionsRouter(WithCachedOriginFunc(func(origin string) bool { return origin == "https://google.com" }, cache))
+	performRequest(router, "GET", "https://google.com")
+	performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, uint64(1), cache.Hits())
+
+	// without origin options, only same-origin requests are allowed
+	router = newOptionsRouter(WithMethods("GET"))
+	w = performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+	w = performRequest(router, "GET", "")
+	assert.DeepEqual(t, consts.StatusOK, w.Code)
+}
+
+func TestNewWithOptionsBadValues(t *testing.T) {
+	assert.Panic(t, func() {
+		WithOrigins("https://*.google.com")
+	})
+	assert.Panic(t, func() {
+		WithOrigins("null")
+	})
+	assert.Panic(t, func() {
+		WithOriginPattern("*")
+	})
+	assert.Panic(t, func() {
+		WithOrigins("google.com")
+	})
+	assert.Panic(t, func() {
+		WithOriginPattern("https://*.*.google.com")
+	})
+	assert.Panic(t, func() {
+		WithOriginCIDRs("10.0.0.0/33")
+	})
+	assert.Panic(t, func() {
+		WithDenyOrigins("evil.com")
+	})
+	assert.Panic(t, func() {
+		WithTiming("https://*.google.com")
+	})
+	assert.Panic(t, func() {
+		NewWithOptions(WithAllOrigins(), WithCredentials())
+	})
+	assert.Panic(t, func() {
+		NewWithOptions(WithCredentials(), WithAllOrigins())
+	})
+	assert.Panic(t, func() {
+		WithOrigins("ws://google.com")
+	})
+	assert.Panic(t, func() {
+		WithPreflightOriginRateLimit(-1, 0)
+	})
+	assert.Panic(t, func() {
+		WithPreflightClientRateLimit(1, -1)
+	})
+	assert.Panic(t, func() {
+		WithPreflightClientRateLimit(0, 2)
+	})
+}
+
+func TestWithNullOrigin(t *testing.T) {
+	router := newOptionsRouter(WithOrigins("https://google.com"), WithMaxAge(time.Hour), WithNullOrigin(false, 0))
+
+	// the max age of the other origins does not apply
+	w := performRequest(router, "OPTIONS", "null")
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	assert.DeepEqual(t, "null", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Max-Age"))
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Allow-Credentials"))
+	w = performRequest(router, "OPTIONS", "https://google.com")
+	assert.DeepEqual(t, "3600", w.Header().Get("Access-Control-Max-Age"))
+
+	router = newOptionsRouter(WithNullOrigin(true, time.Minute))
+	w = performRequest(router, "OPTIONS", "null")
+	assert.DeepEqual(t, "60", w.Header().Get("Access-Control-Max-Age"))
+	assert.DeepEqual(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestWithOriginsSchemas(t *testing.T) {
+	// the schema of an extension origin is enabled by the option
+	router := newOptionsRouter(WithOrigins("chrome-extension://random-extension-id"))
+	w := performRequest(router, "GET", "chrome-extension://random-extension-id")
+	assert.DeepEqual(t, "chrome-extension://random-extension-id", w.Header().Get("Access-Control-Allow-Origin"))
+	w = performRequest(router, "GET", "chrome-extension://other-extension-id")
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+
+	assert.NotNil(t, NewWithOptions(WithDenyOrigins("moz-extension://*"), WithAllOrigins()))
+}
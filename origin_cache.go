@@ -0,0 +1,118 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OriginCache is a bounded LRU cache of AllowOriginFunc decisions, set with
+// Config.AllowOriginFuncCache. It is safe for concurrent use, but must not be shared
+// between policies with different AllowOriginFunc.
+type OriginCache struct {
+	hits   uint64
+	misses uint64
+
+	mu    sync.Mutex
+	ttl   time.Duration
+	cache *lruCache
+	now   func() time.Time
+}
+
+type originDecision struct {
+	allowed bool
+	expires time.Time
+}
+
+// NewOriginCache returns a cache of at most size decisions, each kept for ttl.
+// Decisions never expire if ttl is zero.
+func NewOriginCache(size int, ttl time.Duration) *OriginCache {
+	if size <= 0 {
+		panic("cors: origin cache size must be positive")
+	}
+	return &OriginCache{
+		ttl:   ttl,
+		cache: newLRUCache(size),
+		now:   time.Now,
+	}
+}
+
+// Hits returns the number of decisions found in the cache.
+func (oc *OriginCache) Hits() uint64 {
+	return atomic.LoadUint64(&oc.hits)
+}
+
+// Misses returns the number of decisions not found in the cache, or expired.
+func (oc *OriginCache) Misses() uint64 {
+	return atomic.LoadUint64(&oc.misses)
+}
+
+// Len returns the number of decisions in the cache.
+func (oc *OriginCache) Len() int {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	return oc.cache.len()
+}
+
+// Purge removes all the decisions from the cache, for example after the data behind
+// AllowOriginFunc changed.
+func (oc *OriginCache) Purge() {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	oc.cache.purge()
+}
+
+// allow returns the cached decision of the origin, or calls f and caches its decision.
+// The origin may point into the request, it is copied before it is retained.
+func (oc *OriginCache) allow(origin string, f func(string) bool) bool {
+	oc.mu.Lock()
+	v, ok := oc.cache.get(origin)
+	if ok {
+		d := v.(originDecision)
+		if oc.ttl <= 0 || oc.now().Before(d.expires) {
+			oc.mu.Unlock()
+			atomic.AddUint64(&oc.hits, 1)
+			return d.allowed
+		}
+	}
+	oc.mu.Unlock()
+	atomic.AddUint64(&oc.misses, 1)
+
+	// f runs without the lock, concurrent misses of the same origin may call it twice
+	origin = string(str2bytes(origin))
+	d := originDecision{allowed: f(origin)}
+	if oc.ttl > 0 {
+		d.expires = oc.now().Add(oc.ttl)
+	}
+	oc.mu.Lock()
+	oc.cache.add(origin, d)
+	oc.mu.Unlock()
+	return d.allowed
+}
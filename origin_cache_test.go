@@ -0,0 +1,135 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func TestOriginCache(t *testing.T) {
+	var calls int64
+	cache := NewOriginCache(2, time.Minute)
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+
+	cors := newCors(Config{
+		AllowOriginFunc: func(origin string) bool {
+			atomic.AddInt64(&calls, 1)
+			return origin == "https://google.com"
+		},
+		AllowOriginFuncCache: cache,
+	})
+
+	assert.True(t, cors.validateOrigin("https://google.com"))
+	assert.True(t, cors.validateOrigin("https://google.com"))
+	assert.False(t, cors.validateOrigin("https://example.com"))
+	assert.False(t, cors.validateOrigin("https://example.com"))
+	assert.DeepEqual(t, int64(2), calls)
+	assert.DeepEqual(t, uint64(2), cache.Hits())
+	assert.DeepEqual(t, uint64(2), cache.Misses())
+
+	// expired
+	now = now.Add(time.Minute)
+	assert.True(t, cors.validateOrigin("https://google.com"))
+	assert.DeepEqual(t, int64(3), calls)
+	assert.DeepEqual(t, uint64(3), cache.Misses())
+
+	// bounded
+	assert.False(t, cors.validateOrigin("https://github.com"))
+	assert.DeepEqual(t, 2, cache.Len())
+	assert.False(t, cors.validateOrigin("https://example.com"))
+	assert.DeepEqual(t, int64(5), calls)
+
+	// purge
+	cache.Purge()
+	assert.DeepEqual(t, 0, cache.Len())
+	assert.True(t, cors.validateOrigin("https://google.com"))
+	assert.DeepEqual(t, int64(6), calls)
+
+	assert.Panic(t, func() {
+		NewOriginCache(0, time.Minute)
+	})
+	assert.Panic(t, func() {
+		New(Config{
+			AllowOrigins:         []string{"https://google.com"},
+			AllowOriginFuncCache: NewOriginCache(10, 0),
+		})
+	})
+}
+
+func TestOriginCacheParallel(t *testing.T) {
+	cache := NewOriginCache(16, 0)
+	router := newTestRouter(Config{
+		AllowOriginFunc: func(origin string) bool {
+			return origin != "https://example.com"
+		},
+		AllowOriginFuncCache: cache,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				origin := "https://" + strconv.Itoa((i+j)%32) + ".google.com"
+				w := performRequest(router, "GET", origin)
+				assert.DeepEqual(t, origin, w.Header().Get("Access-Control-Allow-Origin"))
+				w = performRequest(router, "GET", "https://example.com")
+				assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.DeepEqual(t, uint64(1600), cache.Hits()+cache.Misses())
+	assert.True(t, cache.Len() <= 16)
+}
+
+func Benchmark_hertz_cors_origin_cache(b *testing.B) {
+	router := newTestRouter(Config{
+		AllowOriginFunc: func(origin string) bool {
+			return origin == "http://github.com"
+		},
+		AllowOriginFuncCache: NewOriginCache(1024, time.Minute),
+	})
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			performRequest(router, "GET", "http://github.com")
+		}
+	})
+}
@@ -0,0 +1,129 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// portRange is the port constraint of an origin rule like https://portal.example.com:8443-8450,
+// or https://portal.example.com:* for any port.
+type portRange struct {
+	low, high int
+	any       bool
+}
+
+func (r portRange) match(port int) bool {
+	return r.any || port >= r.low && port <= r.high
+}
+
+// defaultPorts are the ports of the origins without an explicit port.
+var defaultPorts = map[string]int{
+	"http://":  80,
+	"https://": 443,
+	"ws://":    80,
+	"wss://":   443,
+}
+
+// splitOriginPort splits an origin like https://example.com:8443 into https://example.com
+// and its port. hasPort is false if the origin has no explicit port.
+func splitOriginPort(origin string) (key, port string, hasPort bool) {
+	i := strings.Index(origin, "://")
+	if i < 0 {
+		return origin, "", false
+	}
+	host := origin[i+3:]
+	j := strings.LastIndexByte(host, ':')
+	if j < 0 || strings.IndexByte(host[j:], ']') >= 0 {
+		return origin, "", false
+	}
+	return origin[:i+3+j], host[j+1:], true
+}
+
+// parsePortRule parses an origin rule with a port range or any port. ok is false if the
+// rule has no port range, and err is set if the port range is malformed.
+func parsePortRule(origin string) (key string, r portRange, ok bool, err error) {
+	key, port, hasPort := splitOriginPort(origin)
+	if !hasPort || (port != "*" && !strings.Contains(port, "-")) {
+		return "", portRange{}, false, nil
+	}
+	if strings.Contains(key, "*") {
+		return "", portRange{}, false, errors.New("port ranges can not be used with wildcards in " + origin)
+	}
+	if port == "*" {
+		return key, portRange{any: true}, true, nil
+	}
+	bounds := strings.SplitN(port, "-", 2)
+	low, err1 := parsePort(bounds[0])
+	high, err2 := parsePort(bounds[1])
+	if err1 != nil || err2 != nil || low > high {
+		return "", portRange{}, false, errors.New("bad port range in " + origin + ", it must be like 8443-8450")
+	}
+	return key, portRange{low: low, high: high}, true, nil
+}
+
+func parsePort(s string) (int, error) {
+	if !isPort(s) {
+		return 0, errors.New("bad port " + s)
+	}
+	return strconv.Atoi(s)
+}
+
+// isPortRule reports whether the origin rule has a port range or any port.
+func isPortRule(origin string) bool {
+	_, _, ok, err := parsePortRule(origin)
+	return ok || err != nil
+}
+
+// matchPort reports whether the origin matches a port rule of the matcher.
+func (m *originMatcher) matchPort(origin string) bool {
+	key, port, hasPort := splitOriginPort(origin)
+	ranges, ok := m.ports[key]
+	if !ok {
+		return false
+	}
+	p := -1
+	if hasPort {
+		if !isPort(port) {
+			return false
+		}
+		p, _ = strconv.Atoi(port)
+	} else if i := strings.Index(key, "://"); i >= 0 {
+		if d, ok := defaultPorts[key[:i+3]]; ok {
+			p = d
+		}
+	}
+	for _, r := range ranges {
+		if r.match(p) {
+			return true
+		}
+	}
+	return false
+}
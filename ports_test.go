@@ -0,0 +1,130 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+func TestParsePortRule(t *testing.T) {
+	key, r, ok, err := parsePortRule("https://portal.example.com:8443-8450")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.DeepEqual(t, "https://portal.example.com", key)
+	assert.DeepEqual(t, portRange{low: 8443, high: 8450}, r)
+
+	key, r, ok, err = parsePortRule("http://[::1]:*")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.DeepEqual(t, "http://[::1]", key)
+	assert.DeepEqual(t, portRange{any: true}, r)
+
+	for _, origin := range []string{"https://portal.example.com", "https://portal.example.com:8443", "http://[::1]", "https://*.example.com"} {
+		_, _, ok, err = parsePortRule(origin)
+		assert.False(t, ok)
+		assert.Nil(t, err)
+	}
+	for _, origin := range []string{
+		"https://portal.example.com:8450-8443",
+		"https://portal.example.com:0-10",
+		"https://portal.example.com:8443-70000",
+		"https://portal.example.com:8443-",
+		"https://portal.example.com:a-b",
+		"https://*.example.com:8443-8450",
+		"https://*.example.com:*",
+	} {
+		_, _, _, err = parsePortRule(origin)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestPortRules(t *testing.T) {
+	router := newTestRouter(Config{
+		AllowOrigins: []string{"https://Portal.example.com:8443-8450", "http://dev.example.com:*", "https://www.example.com:440-450"},
+		DenyOrigins:  []string{"https://portal.example.com:8445-8446"},
+	})
+
+	for _, c := range []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://portal.example.com:8443", true},
+		{"https://portal.example.com:8450", true},
+		{"https://portal.example.com:8442", false},
+		{"https://portal.example.com:8451", false},
+		{"https://portal.example.com:8445", false},
+		{"https://portal.example.com", false},
+		{"http://portal.example.com:8443", false},
+		{"https://portal.example.com.evil.com:8443", false},
+		{"https://evil.portal.example.com:8443", false},
+		{"http://dev.example.com", true},
+		{"http://dev.example.com:1", true},
+		{"http://dev.example.com:65535", true},
+		{"http://dev.example.com:", false},
+		{"http://dev.example.com:abc", false},
+		{"http://dev.example.com:65536", false},
+		{"https://dev.example.com:3000", false},
+		// the default port of https is in the range
+		{"https://www.example.com", true},
+		{"https://www.example.com:451", false},
+	} {
+		w := performRequest(router, "GET", c.origin)
+		if c.allowed {
+			assert.DeepEqual(t, c.origin, w.Header().Get("Access-Control-Allow-Origin"))
+		} else {
+			assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+		}
+	}
+}
+
+func TestPortRulesValidate(t *testing.T) {
+	assert.Nil(t, Config{AllowOrigins: []string{"https://portal.example.com:8443-8450"}}.Validate())
+	assert.DeepEqual(t, "bad origin: bad port range in https://portal.example.com:8450-8443, it must be like 8443-8450",
+		Config{AllowOrigins: []string{"https://portal.example.com:8450-8443"}}.Validate().Error())
+	assert.DeepEqual(t, "bad deny origin: port ranges can not be used with wildcards in https://*.example.com:8443-8450",
+		Config{AllowAllOrigins: true, DenyOrigins: []string{"https://*.example.com:8443-8450"}}.Validate().Error())
+
+	// the schema of a port rule must be allowed
+	assert.Nil(t, Config{AllowOrigins: []string{"https://portal.example.com:*"}}.Validate())
+	assert.Nil(t, Config{AllowOrigins: []string{"file://portal.example.com:*"}, AllowFiles: true}.Validate())
+	for _, origin := range []string{"ftp://portal.example.com:*", "evil://portal.example.com:8443-8450"} {
+		assert.NotNil(t, Config{AllowOrigins: []string{origin}}.Validate())
+		assert.NotNil(t, Config{AllowAllOrigins: true, DenyOrigins: []string{origin}}.Validate())
+	}
+	assert.DeepEqual(t, "bad origin: port rules must include http://,https://",
+		Config{AllowOrigins: []string{"FTP://portal.example.com:*"}}.Validate().Error())
+
+	// port rules are not string wildcards
+	assert.DeepEqual(t, [][]string{{"https://", ".example.com"}}, Config{
+		AllowOrigins:  []string{"https://portal.example.com:*", "https://*.example.com"},
+		AllowWildcard: true,
+	}.parseWildcardRules())
+}
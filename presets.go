@@ -0,0 +1,107 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"strings"
+	"time"
+)
+
+// GRPCWebConfig returns a configuration for gRPC-Web clients, see
+// https://github.com/grpc/grpc/blob/master/doc/PROTOCOL-WEB.md. Calls are POST
+// requests whose status is sent in the Grpc-Status and Grpc-Message headers when the
+// response has no body. The origins must still be set.
+func GRPCWebConfig() Config {
+	return Config{
+		AllowMethods:  []string{"POST", "OPTIONS"},
+		AllowHeaders:  []string{"Content-Type", "X-Grpc-Web", "X-User-Agent", "Grpc-Timeout"},
+		ExposeHeaders: []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"},
+		MaxAge:        12 * time.Hour,
+	}
+}
+
+// TusConfig adds to base what browser uploads with the tus resumable upload protocol
+// need, see https://tus.io/protocols/resumable-upload. The upload is created with POST,
+// resumed with HEAD and PATCH and terminated with DELETE, and the client reads the upload
+// headers and Location of the responses.
+func TusConfig(base Config) Config {
+	return layerConfig(base,
+		[]string{"GET", "POST", "HEAD", "PATCH", "DELETE", "OPTIONS"},
+		[]string{
+			"Content-Type", "Tus-Resumable", "Upload-Length", "Upload-Offset", "Upload-Metadata",
+			"Upload-Defer-Length", "Upload-Concat", "Upload-Checksum", "X-HTTP-Method-Override",
+		},
+		[]string{
+			"Location", "Tus-Resumable", "Tus-Version", "Tus-Max-Size", "Tus-Extension",
+			"Tus-Checksum-Algorithm", "Upload-Offset", "Upload-Length", "Upload-Metadata",
+			"Upload-Defer-Length", "Upload-Concat", "Upload-Expires",
+		},
+	)
+}
+
+// GraphQLConfig adds to base what GraphQL clients need. Operations are sent with GET or
+// POST, and Apollo clients send Apollo-Require-Preflight or X-Apollo-Operation-Name to
+// pass the CSRF prevention of Apollo Server, which only accepts requests that are
+// preflighted.
+func GraphQLConfig(base Config) Config {
+	return layerConfig(base,
+		[]string{"GET", "POST", "OPTIONS"},
+		[]string{
+			"Content-Type", "Apollo-Require-Preflight", "X-Apollo-Operation-Name",
+			"Apollographql-Client-Name", "Apollographql-Client-Version",
+		},
+		nil,
+	)
+}
+
+// layerConfig returns a copy of base with the methods and headers added, skipping the
+// ones already present.
+func layerConfig(base Config, methods, allowHeaders, exposeHeaders []string) Config {
+	c := base
+	c.AllowMethods = appendMissing(base.AllowMethods, methods)
+	c.AllowHeaders = appendMissing(base.AllowHeaders, allowHeaders)
+	c.ExposeHeaders = appendMissing(base.ExposeHeaders, exposeHeaders)
+	return c
+}
+
+func appendMissing(list, values []string) []string {
+	merged := append([]string(nil), list...)
+	for _, v := range values {
+		found := false
+		for _, l := range merged {
+			if strings.EqualFold(strings.TrimSpace(l), v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
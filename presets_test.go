@@ -0,0 +1,213 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/hertz-contrib/cors/corstest"
+)
+
+func TestGRPCWebConfig(t *testing.T) {
+	c := GRPCWebConfig()
+	c.AllowOrigins = []string{"https://app.example.com"}
+	router := route.NewEngine(config.NewOptions([]config.Option{}))
+	router.Use(New(c))
+	router.POST("/helloworld.Greeter/SayHello", func(ctx context.Context, c *app.RequestContext) {
+		// a unary call with an error status and no message, as sent by grpcwebproxy
+		c.Header("Grpc-Status", "5")
+		c.Header("Grpc-Message", "not found")
+		c.Data(consts.StatusOK, "application/grpc-web+proto", nil)
+	})
+
+	// the preflight sent by grpc-web before a unary call
+	w := corstest.Preflight("https://app.example.com", "POST", "content-type", "x-grpc-web", "x-user-agent", "grpc-timeout").
+		WithPath("/helloworld.Greeter/SayHello").Do(router)
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	assert.True(t, corstest.AssertPreflightAllows(t, w, "POST", "content-type", "x-grpc-web", "x-user-agent", "grpc-timeout"))
+
+	// the unary call itself
+	request := &corstest.BrowserRequest{
+		Mode:   corstest.ModeCors,
+		Origin: "https://app.example.com",
+		Method: "POST",
+		Path:   "/helloworld.Greeter/SayHello",
+		Headers: map[string]string{
+			"Content-Type": "application/grpc-web+proto",
+			"X-Grpc-Web":   "1",
+			"X-User-Agent": "grpc-web-javascript/0.1",
+			"Grpc-Timeout": "9998m",
+		},
+	}
+	assert.Nil(t, corstest.Run(router, request))
+
+	_, actual := request.Requests()
+	w = actual.Do(router)
+	assert.DeepEqual(t, consts.StatusOK, w.Code)
+	assert.DeepEqual(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	exposed := strings.Split(w.Header().Get("Access-Control-Expose-Headers"), ",")
+	assert.DeepEqual(t, []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"}, exposed)
+	assert.DeepEqual(t, "5", w.Header().Get("Grpc-Status"))
+
+	// an origin which is not allowed can not call
+	request.Origin = "https://evil.com"
+	assert.NotNil(t, corstest.Run(router, request))
+}
+
+func TestTusConfig(t *testing.T) {
+	base := Config{
+		AllowOrigins:     []string{"https://app.example.com"},
+		AllowMethods:     []string{"get", "POST"},
+		AllowHeaders:     []string{"Authorization"},
+		AllowCredentials: true,
+	}
+	c := TusConfig(base)
+	assert.DeepEqual(t, []string{"get", "POST"}, base.AllowMethods)
+	assert.DeepEqual(t, []string{"get", "POST", "HEAD", "PATCH", "DELETE", "OPTIONS"}, c.AllowMethods)
+	assert.DeepEqual(t, "Authorization", c.AllowHeaders[0])
+	assert.True(t, c.AllowCredentials)
+
+	router := route.NewEngine(config.NewOptions([]config.Option{}))
+	router.Use(New(c))
+	router.POST("/files", func(ctx context.Context, c *app.RequestContext) {
+		c.Header("Tus-Resumable", "1.0.0")
+		c.Header("Location", "https://api.example.com/files/24e533e0")
+		c.Status(consts.StatusCreated)
+	})
+	router.HEAD("/files/:id", func(ctx context.Context, c *app.RequestContext) {
+		c.Header("Tus-Resumable", "1.0.0")
+		c.Header("Upload-Offset", "70")
+		c.Header("Upload-Length", "100")
+		c.Status(consts.StatusOK)
+	})
+	router.PATCH("/files/:id", func(ctx context.Context, c *app.RequestContext) {
+		c.Header("Tus-Resumable", "1.0.0")
+		c.Header("Upload-Offset", "100")
+		c.Status(consts.StatusNoContent)
+	})
+
+	// the requests of tus-js-client to create, resume and finish an upload
+	for _, r := range []struct {
+		request *corstest.BrowserRequest
+		path    string
+		read    []string
+	}{
+		{
+			request: &corstest.BrowserRequest{Method: "POST", Headers: map[string]string{
+				"Tus-Resumable":   "1.0.0",
+				"Upload-Length":   "100",
+				"Upload-Metadata": "filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==",
+				"Authorization":   "Bearer token",
+			}},
+			path: "/files",
+			read: []string{"Location", "Tus-Resumable"},
+		},
+		{
+			request: &corstest.BrowserRequest{Method: "HEAD", Headers: map[string]string{
+				"Tus-Resumable": "1.0.0",
+			}},
+			path: "/files/24e533e0",
+			read: []string{"Upload-Offset", "Upload-Length"},
+		},
+		{
+			request: &corstest.BrowserRequest{Method: "PATCH", Headers: map[string]string{
+				"Tus-Resumable": "1.0.0",
+				"Upload-Offset": "70",
+				"Content-Type":  "application/offset+octet-stream",
+			}},
+			path: "/files/24e533e0",
+			read: []string{"Upload-Offset"},
+		},
+	} {
+		r.request.Mode = corstest.ModeCors
+		r.request.Origin = "https://app.example.com"
+		r.request.Credentials = true
+		r.request.Path = r.path
+
+		preflight, actual := r.request.Requests()
+		assert.NotNil(t, preflight)
+		w := preflight.Do(router)
+		assert.Nil(t, corstest.PreflightCheck(r.request, corstest.ResponseOf(w)))
+		w = actual.Do(router)
+		assert.Nil(t, corstest.CORSCheck(r.request.Origin, true, w.Header()))
+		exposed := w.Header().Get("Access-Control-Expose-Headers")
+		for _, h := range r.read {
+			assert.True(t, strings.Contains(exposed, h))
+		}
+	}
+}
+
+func TestGraphQLConfig(t *testing.T) {
+	base := DefaultConfig()
+	base.AllowOrigins = []string{"https://studio.apollographql.com"}
+	c := GraphQLConfig(base)
+	assert.DeepEqual(t, base.AllowMethods, c.AllowMethods)
+
+	router := route.NewEngine(config.NewOptions([]config.Option{}))
+	router.Use(New(c))
+	router.Any("/graphql", func(ctx context.Context, c *app.RequestContext) {
+		c.JSON(consts.StatusOK, map[string]interface{}{"data": map[string]string{"hello": "world"}})
+	})
+
+	// a query of Apollo Client, with the header passing the CSRF prevention of Apollo Server
+	assert.Nil(t, corstest.Run(router, &corstest.BrowserRequest{
+		Mode:   corstest.ModeCors,
+		Origin: "https://studio.apollographql.com",
+		Method: "POST",
+		Path:   "/graphql",
+		Headers: map[string]string{
+			"Content-Type":                 "application/json",
+			"Apollo-Require-Preflight":     "true",
+			"Apollographql-Client-Name":    "web",
+			"Apollographql-Client-Version": "1.0",
+		},
+	}))
+	// a persisted query sent with GET
+	assert.Nil(t, corstest.Run(router, &corstest.BrowserRequest{
+		Mode:    corstest.ModeCors,
+		Origin:  "https://studio.apollographql.com",
+		Method:  "GET",
+		Path:    "/graphql",
+		Headers: map[string]string{"X-Apollo-Operation-Name": "Hello"},
+	}))
+	// the preset does not allow other origins
+	assert.NotNil(t, corstest.Run(router, &corstest.BrowserRequest{
+		Mode:    corstest.ModeCors,
+		Origin:  "https://evil.com",
+		Method:  "POST",
+		Path:    "/graphql",
+		Headers: map[string]string{"Content-Type": "application/json"},
+	}))
+}
@@ -0,0 +1,122 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	_ "embed" // for the public suffix list
+	"strings"
+	"sync"
+)
+
+// publicSuffixList is a snapshot of https://publicsuffix.org/list/public_suffix_list.dat,
+// with the ICANN and the private domains.
+//
+//go:embed public_suffix_list.dat
+var publicSuffixList string
+
+// publicSuffixes are the rules of the list, parsed on first use.
+type publicSuffixes struct {
+	rules      map[string]struct{}
+	wildcards  map[string]struct{}
+	exceptions map[string]struct{}
+}
+
+var (
+	publicSuffixesOnce sync.Once
+	publicSuffixRules  *publicSuffixes
+)
+
+func loadPublicSuffixes() *publicSuffixes {
+	publicSuffixesOnce.Do(func() {
+		p := &publicSuffixes{
+			rules:      make(map[string]struct{}),
+			wildcards:  make(map[string]struct{}),
+			exceptions: make(map[string]struct{}),
+		}
+		for _, line := range strings.Split(publicSuffixList, "\n") {
+			fields := strings.Fields(line)
+			if len(fields) == 0 || strings.HasPrefix(fields[0], "//") {
+				continue
+			}
+			rule := strings.ToLower(fields[0])
+			switch {
+			case strings.HasPrefix(rule, "!"):
+				p.exceptions[rule[1:]] = struct{}{}
+			case strings.HasPrefix(rule, "*."):
+				p.wildcards[rule[2:]] = struct{}{}
+			default:
+				p.rules[rule] = struct{}{}
+			}
+		}
+		publicSuffixRules = p
+	})
+	return publicSuffixRules
+}
+
+// isPublicSuffix reports whether the domain is a public suffix, under which anyone can
+// register a domain, like com, co.uk or github.io. Domains of a single label are public
+// suffixes by the default rule of the list. Internationalized rules are only matched in
+// their Unicode form.
+func isPublicSuffix(domain string) bool {
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	if !strings.Contains(domain, ".") {
+		return true
+	}
+	p := loadPublicSuffixes()
+	if _, ok := p.rules[domain]; ok {
+		return true
+	}
+	if _, ok := p.exceptions[domain]; ok {
+		return false
+	}
+	_, ok := p.wildcards[domain[strings.IndexByte(domain, '.')+1:]]
+	return ok
+}
+
+// publicSuffixWildcard returns the public suffix whose subdomains are all matched by a
+// wildcard rule like {"https://", ".github.io"}, or false if the fixed part of the rule
+// is more than a public suffix.
+func publicSuffixWildcard(rule []string) (string, bool) {
+	prefix, suffix := rule[0], rule[1]
+	// a trailing * drops the character before it, "https://*" is {"https:/", "*"}. Extension
+	// ids are not registered domains, so only the schemas of web hosts are checked.
+	if suffix == "*" && (contains(DefaultSchemas, prefix+"/") || contains(WebSocketSchemas, prefix+"/")) {
+		return "", true
+	}
+	if prefix != "*" && !strings.HasSuffix(prefix, "://") {
+		return "", false
+	}
+	domain := strings.TrimPrefix(suffix, ".")
+	if i := strings.IndexAny(domain, ":/"); i >= 0 {
+		domain = domain[:i]
+	}
+	if len(domain) == 0 || isPublicSuffix(domain) {
+		return domain, true
+	}
+	return "", false
+}
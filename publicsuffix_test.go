@@ -0,0 +1,101 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+)
+
+func TestIsPublicSuffix(t *testing.T) {
+	for domain, expected := range map[string]bool{
+		"com":         true,
+		"co.uk":       true,
+		"CO.UK.":      true,
+		"github.io":   true,
+		"foo.ck":      true,
+		"www.ck":      false,
+		"example.com": false,
+		"foo.co.uk":   false,
+		"localhost":   true,
+	} {
+		assert.DeepEqual(t, expected, isPublicSuffix(domain))
+	}
+}
+
+func TestPublicSuffixWildcard(t *testing.T) {
+	suffix, ok := publicSuffixWildcard([]string{"https://", ".github.io"})
+	assert.True(t, ok)
+	assert.DeepEqual(t, "github.io", suffix)
+
+	suffix, ok = publicSuffixWildcard([]string{"*", ".co.uk:8080"})
+	assert.True(t, ok)
+	assert.DeepEqual(t, "co.uk", suffix)
+
+	_, ok = publicSuffixWildcard([]string{"https://", ".foo.com"})
+	assert.False(t, ok)
+	_, ok = publicSuffixWildcard([]string{"https://api.", ".com"})
+	assert.False(t, ok)
+	_, ok = publicSuffixWildcard([]string{"https://api", "*"})
+	assert.False(t, ok)
+
+	suffix, ok = publicSuffixWildcard([]string{"https:/", "*"})
+	assert.True(t, ok)
+	assert.DeepEqual(t, "", suffix)
+	_, ok = publicSuffixWildcard([]string{"chrome-extension:/", "*"})
+	assert.False(t, ok)
+}
+
+func TestPublicSuffixWildcardValidate(t *testing.T) {
+	config := Config{AllowWildcard: true, AllowOrigins: []string{"https://*.github.io"}}
+	assert.DeepEqual(t, "bad origin: https://*.github.io matches every domain under the public suffix github.io, set AllowPublicSuffixWildcard if it is intended",
+		config.Validate().Error())
+	assert.NotNil(t, Config{AllowWildcard: true, AllowOrigins: []string{"*.co.uk"}}.Validate())
+	assert.NotNil(t, Config{AllowWildcard: true, AllowOrigins: []string{"https://*.*.com"}}.Validate())
+	assert.Nil(t, Config{AllowWildcard: true, AllowOrigins: []string{"https://*.foo.co.uk", "https://api.*"}}.Validate())
+	assert.Nil(t, Config{AllowWildcard: true, AllowOrigins: []string{"https://foo.com:*"}}.Validate())
+
+	// a wildcard over the whole host matches every domain of the schema
+	assert.DeepEqual(t, "bad origin: https://* matches every domain, set AllowPublicSuffixWildcard if it is intended",
+		Config{AllowWildcard: true, AllowOrigins: []string{"https://*"}}.Validate().Error())
+	assert.NotNil(t, Config{AllowWildcard: true, AllowOrigins: []string{"http://*"}}.Validate())
+	assert.NotNil(t, Config{AllowWildcard: true, AllowOrigins: []string{"https://*:8443"}}.Validate())
+	assert.Nil(t, Config{AllowWildcard: true, AllowPublicSuffixWildcard: true, AllowOrigins: []string{"https://*"}}.Validate())
+
+	config.AllowPublicSuffixWildcard = true
+	assert.Nil(t, config.Validate())
+	assert.NotNil(t, Config{AllowOrigins: []string{"https://foo.com"}, AllowPublicSuffixWildcard: true}.Validate())
+}
+
+func TestWithPublicSuffixWildcard(t *testing.T) {
+	assert.Panic(t, func() {
+		NewWithOptions(WithOriginPattern("https://*.github.io"))
+	})
+	assert.NotNil(t, NewWithOptions(WithOriginPattern("https://*.github.io"), WithPublicSuffixWildcard()))
+}
@@ -0,0 +1,159 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// ErrPreflightRateLimited is attached to the request context when a preflight request is
+// rejected by the preflight rate limit.
+var ErrPreflightRateLimited = errors.New("cors: preflight rate limited")
+
+// defaultPreflightRateLimitSize is the default number of origins and client IPs tracked
+// by the preflight rate limit.
+const defaultPreflightRateLimitSize = 10000
+
+// tokenBucket is a token bucket refilled at the rate of its limit.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// bucketLimit is the rate and the burst of a set of token buckets, tracked in an LRU
+// cache to bound the memory. The buckets are nil if the limit is disabled.
+type bucketLimit struct {
+	rate    float64
+	burst   float64
+	buckets *lruCache
+}
+
+func newBucketLimit(rate float64, burst, size int) bucketLimit {
+	if rate <= 0 {
+		return bucketLimit{}
+	}
+	if burst <= 0 {
+		burst = int(math.Ceil(rate))
+	}
+	return bucketLimit{rate: rate, burst: float64(burst), buckets: newLRUCache(size)}
+}
+
+// preflightLimiter limits preflight requests per origin and per client IP with token
+// buckets, each limit can be disabled.
+type preflightLimiter struct {
+	mu      sync.Mutex
+	origins bucketLimit
+	clients bucketLimit
+	now     func() time.Time
+}
+
+func newPreflightLimiter(c Config) *preflightLimiter {
+	if c.PreflightOriginRateLimit <= 0 && c.PreflightClientRateLimit <= 0 {
+		return nil
+	}
+	size := c.PreflightRateLimitSize
+	if size <= 0 {
+		size = defaultPreflightRateLimitSize
+	}
+	return &preflightLimiter{
+		origins: newBucketLimit(c.PreflightOriginRateLimit, c.PreflightOriginBurst, size),
+		clients: newBucketLimit(c.PreflightClientRateLimit, c.PreflightClientBurst, size),
+		now:     time.Now,
+	}
+}
+
+// allow takes a token from the buckets of the origin and the client IP. If either is
+// empty, nothing is taken and it returns how long to wait for the next token.
+func (l *preflightLimiter) allow(origin, ip string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	o := l.origins.bucket(origin, now)
+	i := l.clients.bucket(ip, now)
+	wait := math.Max(l.origins.wait(o), l.clients.wait(i))
+	if wait > 0 {
+		return time.Duration(wait * float64(time.Second)), false
+	}
+	if o != nil {
+		o.tokens--
+	}
+	if i != nil {
+		i.tokens--
+	}
+	return 0, true
+}
+
+// bucket returns the refilled bucket of the key, or nil if the limit is disabled.
+func (l bucketLimit) bucket(key string, now time.Time) *tokenBucket {
+	if l.buckets == nil {
+		return nil
+	}
+	v, ok := l.buckets.get(key)
+	if !ok {
+		b := &tokenBucket{tokens: l.burst, last: now}
+		// key may point into the request, it must be copied to be retained
+		l.buckets.add(string(str2bytes(key)), b)
+		return b
+	}
+	b := v.(*tokenBucket)
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	return b
+}
+
+// wait returns the seconds until the bucket has a token, zero if it has one.
+func (l bucketLimit) wait(b *tokenBucket) float64 {
+	if b == nil || b.tokens >= 1 {
+		return 0
+	}
+	return (1 - b.tokens) / l.rate
+}
+
+// allowPreflight applies the preflight rate limit, and answers 429 with Retry-After
+// when it is hit.
+func (cors *cors) allowPreflight(c *app.RequestContext, origin string) bool {
+	wait, ok := cors.preflightLimiter.allow(origin, c.ClientIP())
+	if ok {
+		return true
+	}
+	retryAfter := int64(math.Ceil(wait.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Response.Header.Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+	_ = c.AbortWithError(consts.StatusTooManyRequests, ErrPreflightRateLimited)
+	return false
+}
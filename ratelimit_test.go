@@ -0,0 +1,172 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+func TestPreflightRateLimit(t *testing.T) {
+	cors := newCors(Config{
+		AllowOrigins:             []string{"https://google.com", "https://github.com"},
+		PreflightOriginRateLimit: 1,
+		PreflightOriginBurst:     2,
+		PreflightClientRateLimit: 1,
+		PreflightClientBurst:     2,
+	})
+	now := time.Unix(0, 0)
+	cors.preflightLimiter.now = func() time.Time { return now }
+	router := route.NewEngine(config.NewOptions([]config.Option{}))
+	router.Use(cors.handle)
+
+	// burst
+	for i := 0; i < 2; i++ {
+		w := performRequest(router, "OPTIONS", "https://google.com")
+		assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	}
+	w := performRequest(router, "OPTIONS", "https://google.com")
+	assert.DeepEqual(t, consts.StatusTooManyRequests, w.Code)
+	assert.DeepEqual(t, "1", w.Header().Get("Retry-After"))
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+
+	// actual requests are not limited
+	w = performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, consts.StatusNotFound, w.Code)
+	assert.DeepEqual(t, "https://google.com", w.Header().Get("Access-Control-Allow-Origin"))
+
+	// the bucket of the client IP is empty too
+	w = performRequest(router, "OPTIONS", "https://github.com")
+	assert.DeepEqual(t, consts.StatusTooManyRequests, w.Code)
+
+	// refill
+	now = now.Add(time.Second)
+	w = performRequest(router, "OPTIONS", "https://google.com")
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	w = performRequest(router, "OPTIONS", "https://google.com")
+	assert.DeepEqual(t, consts.StatusTooManyRequests, w.Code)
+
+	// another client IP still shares the bucket of the origin
+	now = now.Add(10 * time.Second)
+	h := ut.Header{Key: "X-Forwarded-For", Value: "10.0.0.1"}
+	for i := 0; i < 2; i++ {
+		w = performRequest(router, "OPTIONS", "https://google.com", h)
+		assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	}
+	w = performRequest(router, "OPTIONS", "https://google.com", h)
+	assert.DeepEqual(t, consts.StatusTooManyRequests, w.Code)
+}
+
+func TestPreflightRateLimitSeparate(t *testing.T) {
+	cors := newCors(Config{
+		AllowOrigins:             []string{"https://google.com", "https://github.com"},
+		PreflightClientRateLimit: 1,
+	})
+	now := time.Unix(0, 0)
+	cors.preflightLimiter.now = func() time.Time { return now }
+	router := route.NewEngine(config.NewOptions([]config.Option{}))
+	router.Use(cors.handle)
+
+	// only the client IP is limited
+	assert.Nil(t, cors.preflightLimiter.origins.buckets)
+	w := performRequest(router, "OPTIONS", "https://google.com")
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	w = performRequest(router, "OPTIONS", "https://github.com")
+	assert.DeepEqual(t, consts.StatusTooManyRequests, w.Code)
+	w = performRequest(router, "OPTIONS", "https://google.com", ut.Header{Key: "X-Forwarded-For", Value: "10.0.0.1"})
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+
+	cors = newCors(Config{
+		AllowOrigins:             []string{"https://google.com", "https://github.com"},
+		PreflightOriginRateLimit: 100,
+		PreflightClientRateLimit: 1,
+	})
+	cors.preflightLimiter.now = func() time.Time { return now }
+	router = route.NewEngine(config.NewOptions([]config.Option{}))
+	router.Use(cors.handle)
+
+	// a large origin limit is shared by the clients of the origin
+	for i := 0; i < 100; i++ {
+		w = performRequest(router, "OPTIONS", "https://google.com", ut.Header{Key: "X-Forwarded-For", Value: "10.0.0." + strconv.Itoa(i)})
+		assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+	}
+	w = performRequest(router, "OPTIONS", "https://google.com", ut.Header{Key: "X-Forwarded-For", Value: "10.0.1.1"})
+	assert.DeepEqual(t, consts.StatusTooManyRequests, w.Code)
+	w = performRequest(router, "OPTIONS", "https://github.com", ut.Header{Key: "X-Forwarded-For", Value: "10.0.1.1"})
+	assert.DeepEqual(t, consts.StatusNoContent, w.Code)
+}
+
+func TestPreflightRateLimitBounded(t *testing.T) {
+	cors := newCors(Config{
+		AllowAllOrigins:          true,
+		PreflightOriginRateLimit: 1,
+		PreflightClientRateLimit: 1,
+		PreflightRateLimitSize:   10,
+	})
+	for i := 0; i < 100; i++ {
+		cors.preflightLimiter.allow("https://"+strconv.Itoa(i)+".example.com", strconv.Itoa(i))
+	}
+	assert.DeepEqual(t, 10, cors.preflightLimiter.origins.buckets.len())
+	assert.DeepEqual(t, 10, cors.preflightLimiter.clients.buckets.len())
+
+	assert.Nil(t, newCors(Config{AllowAllOrigins: true}).preflightLimiter)
+	assert.Panic(t, func() {
+		New(Config{
+			AllowAllOrigins:      true,
+			PreflightOriginBurst: 10,
+		})
+	})
+	assert.Panic(t, func() {
+		New(Config{
+			AllowAllOrigins:          true,
+			PreflightClientRateLimit: -1,
+		})
+	})
+}
+
+func TestPreflightRateLimitKeyCopied(t *testing.T) {
+	l := newCors(Config{
+		AllowAllOrigins:          true,
+		PreflightOriginRateLimit: 1,
+	}).preflightLimiter
+
+	// the origin points into the request, which is reused by the next request
+	b := []byte("https://google.com")
+	_, ok := l.allow(bytes2str(b), "10.0.0.1")
+	assert.True(t, ok)
+	copy(b, "https://example.c")
+	_, ok = l.allow("https://google.com", "10.0.0.2")
+	assert.False(t, ok)
+}
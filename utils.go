@@ -30,6 +30,7 @@ package cors
 import (
 	"bytes"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -38,6 +39,29 @@ import (
 
 type converter func(string) string
 
+// header is a response header precomputed per policy.
+type header struct {
+	key   []byte
+	value []byte
+}
+
+// compileHeaders turns the generated headers into a list in a stable order, which is
+// written without any conversion.
+func compileHeaders(headers map[string]string) []header {
+	keys := make([]string, 0, len(headers))
+	for key, value := range headers {
+		if len(value) > 0 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	compiled := make([]header, 0, len(keys))
+	for _, key := range keys {
+		compiled = append(compiled, header{key: []byte(key), value: []byte(headers[key])})
+	}
+	return compiled
+}
+
 func generateNormalHeaders(c Config) map[string]string {
 	headers := make(map[string]string)
 	if c.AllowCredentials {
@@ -49,7 +73,11 @@ func generateNormalHeaders(c Config) map[string]string {
 	}
 	if c.AllowAllOrigins {
 		headers["Access-Control-Allow-Origin"] = "*"
-	} else {
+		if c.AllowTiming {
+			headers["Timing-Allow-Origin"] = "*"
+		}
+	}
+	if !c.AllowAllOrigins || c.hasDenyRules() {
 		headers["Vary"] = "Origin"
 	}
 	return headers
@@ -76,16 +104,54 @@ func generatePreflightHeaders(c Config) map[string]string {
 	}
 	if c.AllowAllOrigins {
 		headers["Access-Control-Allow-Origin"] = "*"
-	} else {
-		// Always set Vary headers
-		// see https://github.com/rs/cors/issues/10,
-		// https://github.com/rs/cors/commit/dbdca4d95feaa7511a46e6f1efb3b3aa505bc43f#commitcomment-12352001
-
+	}
+	// Always set Vary headers, even with "*" if denied origins get another response
+	// see https://github.com/rs/cors/issues/10,
+	// https://github.com/rs/cors/commit/dbdca4d95feaa7511a46e6f1efb3b3aa505bc43f#commitcomment-12352001
+	if !c.AllowAllOrigins || c.hasDenyRules() {
 		headers["Vary"] = "Origin"
 	}
 	return headers
 }
 
+// hasDenyRules reports whether some origins are denied by DenyOrigins or DenyOriginPatterns.
+func (c Config) hasDenyRules() bool {
+	return len(c.DenyOrigins) > 0 || len(c.DenyOriginPatterns) > 0
+}
+
+// generateCrossOriginIsolationHeaders generates the COOP, COEP and CORP headers, which
+// are sent on every response, cross-origin or not.
+func generateCrossOriginIsolationHeaders(c Config) map[string]string {
+	headers := make(map[string]string)
+	if len(c.CrossOriginOpenerPolicy) > 0 {
+		headers["Cross-Origin-Opener-Policy"] = c.CrossOriginOpenerPolicy
+	}
+	if len(c.CrossOriginEmbedderPolicy) > 0 {
+		headers["Cross-Origin-Embedder-Policy"] = c.CrossOriginEmbedderPolicy
+	}
+	if len(c.CrossOriginResourcePolicy) > 0 {
+		headers["Cross-Origin-Resource-Policy"] = c.CrossOriginResourcePolicy
+	}
+	return headers
+}
+
+// generateNullOriginNormalHeaders generates the headers of an actual request from the
+// null origin, which follows its own credentials rule and never uses the wildcard.
+func generateNullOriginNormalHeaders(c Config) map[string]string {
+	c.AllowAllOrigins = false
+	c.AllowCredentials = c.AllowNullOriginCredentials
+	return generateNormalHeaders(c)
+}
+
+// generateNullOriginPreflightHeaders generates the headers of a preflight request from
+// the null origin, which follows its own credentials and max age rules.
+func generateNullOriginPreflightHeaders(c Config) map[string]string {
+	c.AllowAllOrigins = false
+	c.AllowCredentials = c.AllowNullOriginCredentials
+	c.MaxAge = c.NullOriginMaxAge
+	return generatePreflightHeaders(c)
+}
+
 func normalize(values []string) []string {
 	if values == nil {
 		return nil
@@ -103,6 +169,15 @@ func normalize(values []string) []string {
 	return normalized
 }
 
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 func convert(s []string, c converter) []string {
 	var out []string
 	for _, i := range s {
@@ -126,12 +201,14 @@ func bytes2str(b []byte) string {
 	return *(*string)(unsafe.Pointer(&b))
 }
 
-func compareByteSlices(origin []byte, patterns ...[]byte) int {
-	cmp := make([]byte, 0, len(origin))
-	for _, v := range patterns {
-		cmp = append(cmp, v...)
+// isSameOrigin reports whether the http or https origin is the origin of the host.
+func isSameOrigin(origin, host []byte) bool {
+	for _, schema := range DefaultSchemasBytes {
+		if bytes.HasPrefix(origin, schema) && bytes.Equal(origin[len(schema):], host) {
+			return true
+		}
 	}
-	return bytes.Compare(origin, cmp)
+	return false
 }
 
 func normalizeHeaderKey(s string) string {
@@ -0,0 +1,67 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"bytes"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+var websocketToken = []byte("websocket")
+
+// OriginChecker returns a function reporting whether the origin of a WebSocket handshake
+// is allowed by the origin rules of the config. It can be used as the CheckOrigin of the
+// Upgrader of hertz-contrib/websocket, as browsers do not apply CORS to WebSockets:
+//
+//	upgrader := websocket.HertzUpgrader{
+//		CheckOrigin: cors.OriginChecker(config),
+//	}
+//
+// Handshakes without Origin, which are not sent by browsers, and same-origin handshakes
+// are allowed.
+func OriginChecker(config Config) func(c *app.RequestContext) bool {
+	cors := newCors(config)
+	return func(c *app.RequestContext) bool {
+		return cors.checkWebSocketOrigin(c) == nil
+	}
+}
+
+// checkWebSocketOrigin checks the origin of a WebSocket handshake.
+func (cors *cors) checkWebSocketOrigin(c *app.RequestContext) error {
+	o := c.Request.Header.Peek("Origin")
+	if len(o) == 0 || isSameOrigin(o, c.Request.Host()) {
+		return nil
+	}
+	return cors.checkOrigin(bytes2str(o))
+}
+
+// isWebSocketUpgrade reports whether the request is a WebSocket handshake.
+func isWebSocketUpgrade(c *app.RequestContext) bool {
+	return bytes.EqualFold(c.Request.Header.Peek("Upgrade"), websocketToken)
+}
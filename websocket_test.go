@@ -0,0 +1,108 @@
+/*
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2016 Gin-Gonic
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in
+ * all copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+ * THE SOFTWARE.
+
+ * This file may have been modified by CloudWeGo authors. All CloudWeGo
+ * Modifications are Copyright 2022 CloudWeGo Authors.
+ */
+
+package cors
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/assert"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+var webSocketHeaders = []ut.Header{
+	{Key: "Upgrade", Value: "websocket"},
+	{Key: "Connection", Value: "Upgrade"},
+	{Key: "Sec-WebSocket-Version", Value: "13"},
+	{Key: "Sec-WebSocket-Key", Value: "dGhlIHNhbXBsZSBub25jZQ=="},
+}
+
+func TestOriginChecker(t *testing.T) {
+	check := OriginChecker(Config{
+		AllowOrigins:  []string{"https://google.com", "https://*.github.com"},
+		AllowWildcard: true,
+		DenyOrigins:   []string{"https://evil.github.com"},
+	})
+
+	for _, c := range []struct {
+		origin string
+		host   string
+		ok     bool
+	}{
+		{"https://google.com", "api.com", true},
+		{"https://www.github.com", "api.com", true},
+		{"https://evil.github.com", "api.com", false},
+		{"https://example.com", "api.com", false},
+		{"https://example.com", "example.com", true},
+		{"null", "api.com", false},
+		{"", "api.com", true},
+	} {
+		ctx := app.NewContext(0)
+		ctx.Request.SetHost(c.host)
+		if len(c.origin) > 0 {
+			ctx.Request.Header.Set("Origin", c.origin)
+		}
+		assert.DeepEqual(t, c.ok, check(ctx))
+	}
+
+	assert.Panic(t, func() {
+		OriginChecker(Config{})
+	})
+}
+
+func TestWebSocketUpgrade(t *testing.T) {
+	router := newTestRouter(Config{
+		AllowOrigins:    []string{"https://google.com"},
+		AllowMethods:    []string{"GET"},
+		AllowWebSockets: true,
+	})
+
+	w := performRequest(router, "GET", "https://google.com", webSocketHeaders...)
+	assert.DeepEqual(t, consts.StatusOK, w.Code)
+	assert.DeepEqual(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.DeepEqual(t, "", w.Header().Get("Vary"))
+
+	w = performRequest(router, "GET", "https://example.com", webSocketHeaders...)
+	assert.DeepEqual(t, consts.StatusForbidden, w.Code)
+
+	w = performRequest(router, "GET", "", webSocketHeaders...)
+	assert.DeepEqual(t, consts.StatusOK, w.Code)
+
+	// other requests are still CORS requests
+	w = performRequest(router, "GET", "https://google.com")
+	assert.DeepEqual(t, "https://google.com", w.Header().Get("Access-Control-Allow-Origin"))
+
+	// without AllowWebSockets, the handshake is handled like any other request
+	router = newTestRouter(Config{
+		AllowOrigins: []string{"https://google.com"},
+		AllowMethods: []string{"GET"},
+	})
+	w = performRequest(router, "GET", "https://google.com", webSocketHeaders...)
+	assert.DeepEqual(t, "https://google.com", w.Header().Get("Access-Control-Allow-Origin"))
+}